@@ -3,7 +3,9 @@ module github.com/nickwells/semvertools
 go 1.26.0
 
 require (
+	github.com/nickwells/check.mod/v2 v2.1.29
 	github.com/nickwells/filecheck.mod v1.2.13
+	github.com/nickwells/fileparse.mod v1.1.39
 	github.com/nickwells/location.mod v1.2.37
 	github.com/nickwells/param.mod/v7 v7.2.2
 	github.com/nickwells/semver.mod/v3 v3.2.3
@@ -19,11 +21,9 @@ require (
 )
 
 require (
-	github.com/nickwells/check.mod/v2 v2.1.29 // indirect
 	github.com/nickwells/checksetter.mod/v4 v4.0.34 // indirect
 	github.com/nickwells/english.mod v1.2.10 // indirect
 	github.com/nickwells/errutil.mod v1.2.24 // indirect
-	github.com/nickwells/fileparse.mod v1.1.39 // indirect
 	github.com/nickwells/mathutil.mod/v2 v2.5.11 // indirect
 	github.com/nickwells/pager.mod v1.1.0 // indirect
 	github.com/nickwells/tempus.mod v1.2.11 // indirect
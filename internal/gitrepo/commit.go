@@ -0,0 +1,248 @@
+package gitrepo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature records who made a commit or tag and when
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// parseSignature parses a signature line such as
+//
+//	A U Thor <author@example.com> 1700000000 +0100
+func parseSignature(s string) (Signature, error) {
+	lt := strings.Index(s, "<")
+	gt := strings.LastIndex(s, ">")
+
+	if lt < 0 || gt < lt {
+		return Signature{}, fmt.Errorf("bad signature: %q", s)
+	}
+
+	sig := Signature{
+		Name:  strings.TrimSpace(s[:lt]),
+		Email: s[lt+1 : gt],
+	}
+
+	fields := strings.Fields(s[gt+1:])
+	if len(fields) != 2 { //nolint:mnd
+		return sig, fmt.Errorf("bad signature: %q: no timestamp", s)
+	}
+
+	secs, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return sig, fmt.Errorf("bad signature: %q: bad timestamp", s)
+	}
+
+	tz, err := time.Parse("-0700", fields[1])
+	if err != nil {
+		return sig, fmt.Errorf("bad signature: %q: bad time zone", s)
+	}
+
+	sig.When = time.Unix(secs, 0).In(tz.Location())
+
+	return sig, nil
+}
+
+// header records a single header from a commit or tag object
+type header struct {
+	name  string
+	value string
+}
+
+// parseHeaders splits the object data into the headers and the message.
+// Continuation lines (starting with a space) are added to the value of the
+// preceding header.
+func parseHeaders(data []byte) ([]header, string) {
+	hdrPart, msg, _ := bytes.Cut(data, []byte("\n\n"))
+
+	var hdrs []header
+
+	for line := range strings.SplitSeq(string(hdrPart), "\n") {
+		if strings.HasPrefix(line, " ") && len(hdrs) > 0 {
+			hdrs[len(hdrs)-1].value += "\n" + line[1:]
+			continue
+		}
+
+		name, value, _ := strings.Cut(line, " ")
+		hdrs = append(hdrs, header{name: name, value: value})
+	}
+
+	return hdrs, string(msg)
+}
+
+// Commit records the details of a commit object
+type Commit struct {
+	Hash      string
+	Tree      string
+	Parents   []string
+	Author    Signature
+	Committer Signature
+	Message   string
+}
+
+// Subject returns the first line of the commit message
+func (c Commit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// ReadCommit reads the commit object with the given hash
+func (r *Repo) ReadCommit(hash string) (*Commit, error) {
+	objType, data, err := r.ReadObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if objType != ObjCommit {
+		return nil, fmt.Errorf("object %s is a %s not a %s",
+			hash, objType, ObjCommit)
+	}
+
+	hdrs, msg := parseHeaders(data)
+	c := &Commit{Hash: hash, Message: msg}
+
+	for _, h := range hdrs {
+		switch h.name {
+		case "tree":
+			c.Tree = h.value
+		case "parent":
+			c.Parents = append(c.Parents, h.value)
+		case "author":
+			c.Author, err = parseSignature(h.value)
+		case "committer":
+			c.Committer, err = parseSignature(h.value)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", hash, err)
+		}
+	}
+
+	if c.Tree == "" {
+		return nil, fmt.Errorf("commit %s: there is no tree", hash)
+	}
+
+	return c, nil
+}
+
+// Tag records the details of an annotated tag object
+type Tag struct {
+	Hash       string
+	Object     string
+	ObjectType string
+	Name       string
+	Tagger     Signature
+	HasTagger  bool
+	Message    string
+}
+
+// ReadTag reads the annotated tag object with the given hash
+func (r *Repo) ReadTag(hash string) (*Tag, error) {
+	objType, data, err := r.ReadObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if objType != ObjTag {
+		return nil, fmt.Errorf("object %s is a %s not a %s",
+			hash, objType, ObjTag)
+	}
+
+	hdrs, msg := parseHeaders(data)
+	t := &Tag{Hash: hash, Message: msg}
+
+	for _, h := range hdrs {
+		switch h.name {
+		case "object":
+			t.Object = h.value
+		case "type":
+			t.ObjectType = h.value
+		case "tag":
+			t.Name = h.value
+		case "tagger":
+			t.Tagger, err = parseSignature(h.value)
+			t.HasTagger = true
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", hash, err)
+		}
+	}
+
+	if t.Object == "" {
+		return nil, fmt.Errorf("tag %s: there is no object", hash)
+	}
+
+	return t, nil
+}
+
+// maxTagDepth is the greatest number of tag objects that will be followed
+// when peeling a reference
+const maxTagDepth = 10
+
+// Peel follows any annotated tags from the object with the given hash and
+// returns the hash of the first object which is not a tag
+func (r *Repo) Peel(hash string) (string, error) {
+	for range maxTagDepth {
+		objType, _, err := r.ReadObject(hash)
+		if err != nil {
+			return "", err
+		}
+
+		if objType != ObjTag {
+			return hash, nil
+		}
+
+		t, err := r.ReadTag(hash)
+		if err != nil {
+			return "", err
+		}
+
+		hash = t.Object
+	}
+
+	return "", errors.New("too many nested tags from " + hash)
+}
+
+// RefTime returns the time at which the reference was made. For an
+// annotated tag this is the time the tag was made; otherwise it is the
+// commit time of the commit the reference refers to.
+func (r *Repo) RefTime(ref Ref) (time.Time, error) {
+	objType, _, err := r.ReadObject(ref.Hash)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	hash := ref.Hash
+
+	if objType == ObjTag {
+		t, err := r.ReadTag(hash)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if t.HasTagger {
+			return t.Tagger.When, nil
+		}
+
+		if hash, err = r.Peel(hash); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	c, err := r.ReadCommit(hash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", ref.Name, err)
+	}
+
+	return c.Committer.When, nil
+}
@@ -0,0 +1,101 @@
+package gitrepo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// These are the types of the objects held in a repository
+const (
+	ObjCommit = "commit"
+	ObjTree   = "tree"
+	ObjBlob   = "blob"
+	ObjTag    = "tag"
+)
+
+const (
+	hashLen    = 20
+	hexHashLen = 2 * hashLen
+)
+
+// checkHash returns an error if the hash is not a well-formed hex-encoded
+// object ID
+func checkHash(hash string) error {
+	if len(hash) != hexHashLen {
+		return fmt.Errorf("bad object ID: %q - it must be %d characters long",
+			hash, hexHashLen)
+	}
+
+	if _, err := hex.DecodeString(hash); err != nil {
+		return fmt.Errorf("bad object ID: %q - %w", hash, err)
+	}
+
+	return nil
+}
+
+// ReadObject returns the type and the content of the object with the given
+// hash. The object may be stored loose or in a pack file.
+func (r *Repo) ReadObject(hash string) (string, []byte, error) {
+	if err := checkHash(hash); err != nil {
+		return "", nil, err
+	}
+
+	objType, data, err := r.readLooseObject(hash)
+	if err == nil {
+		return objType, data, nil
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", nil, err
+	}
+
+	return r.readPackedObject(hash)
+}
+
+// readLooseObject reads the object from the loose objects directory. If
+// there is no such object the error will wrap os.ErrNotExist.
+func (r *Repo) readLooseObject(hash string) (string, []byte, error) {
+	fName := filepath.Join(r.commonDir, "objects", hash[:2], hash[2:])
+
+	f, err := os.Open(fName) //nolint:gosec
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read object %s: %w", hash, err)
+	}
+	defer zr.Close()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot read object %s: %w", hash, err)
+	}
+
+	header, data, ok := bytes.Cut(content, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("bad object %s: no header", hash)
+	}
+
+	objType, sizeStr, ok := strings.Cut(string(header), " ")
+	if !ok {
+		return "", nil, fmt.Errorf("bad object %s: bad header: %q", hash, header)
+	}
+
+	size, err := strconv.Atoi(sizeStr)
+	if err != nil || size != len(data) {
+		return "", nil, fmt.Errorf("bad object %s: bad size: %q", hash, sizeStr)
+	}
+
+	return objType, data, nil
+}
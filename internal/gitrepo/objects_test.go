@@ -0,0 +1,193 @@
+package gitrepo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickwells/semvertools/internal/gittest"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// dateEnv returns the environment settings to give both the author and
+// committer dates
+func dateEnv(when time.Time) []string {
+	d := when.Format(time.RFC3339)
+
+	return []string{"GIT_AUTHOR_DATE=" + d, "GIT_COMMITTER_DATE=" + d}
+}
+
+// testRepoStart is the time of the first commit in the test repository
+var testRepoStart = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// mkGitRepo creates a repository with a series of commits, each of which
+// changes a file a little, so that packing the repository will generate
+// deltas. Each commit is given a lightweight tag and every other commit
+// is also given an annotated tag, made an hour after the commit.
+func mkGitRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := t.TempDir()
+	gittest.Run(t, dir, nil, "init", "-q", "-b", "main")
+
+	var content strings.Builder
+
+	for i := range 20 {
+		for j := range 50 {
+			fmt.Fprintf(&content, "line %d of commit %d\n", j, i)
+		}
+
+		fName := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(fName, []byte(content.String()), 0o600); err != nil {
+			t.Fatal("cannot write", fName, ":", err)
+		}
+
+		when := testRepoStart.Add(time.Duration(i) * 24 * time.Hour)
+		gittest.Run(t, dir, dateEnv(when), "add", "file.txt")
+		gittest.Run(t, dir, dateEnv(when),
+			"commit", "-q", "-m", fmt.Sprintf("commit %d\n\nbody %d", i, i))
+		gittest.Run(t, dir, nil, "tag", fmt.Sprintf("v1.0.%d", i))
+
+		if i%2 == 0 {
+			gittest.Run(t, dir, dateEnv(when.Add(time.Hour)),
+				"tag", "-a", "-m", "annotated", fmt.Sprintf("v2.0.%d", i))
+		}
+	}
+
+	return dir
+}
+
+// expRefTime returns the expected time for the tag
+func expRefTime(name string) time.Time {
+	var major, minor, patch int
+
+	_, _ = fmt.Sscanf(name, "refs/tags/v%d.%d.%d", &major, &minor, &patch)
+
+	when := testRepoStart.Add(time.Duration(patch) * 24 * time.Hour)
+	if major == 2 { //nolint:mnd
+		when = when.Add(time.Hour)
+	}
+
+	return when
+}
+
+// checkRepo checks that every object in the repository can be read and
+// matches the content reported by git and that the tag times are correct
+func checkRepo(t *testing.T, id, dir string) {
+	t.Helper()
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(id, ": cannot open the repository:", err)
+	}
+	defer r.Close()
+
+	objects := gittest.Run(t, dir, nil,
+		"cat-file", "--batch-all-objects", "--batch-check")
+
+	for line := range strings.SplitSeq(strings.TrimSpace(objects), "\n") {
+		fields := strings.Fields(line)
+		hash, objType := fields[0], fields[1]
+
+		gotType, gotData, err := r.ReadObject(hash)
+		if err != nil {
+			t.Error(id, ": cannot read object", hash, ":", err)
+			continue
+		}
+
+		testhelper.DiffString(t, id+": "+hash, "type", gotType, objType)
+		testhelper.DiffString(t, id+": "+hash, "content",
+			string(gotData), gittest.Run(t, dir, nil, "cat-file", objType, hash))
+	}
+
+	tags, err := r.Tags()
+	if err != nil {
+		t.Fatal(id, ": cannot read the tags:", err)
+	}
+
+	testhelper.DiffInt(t, id, "tag count", len(tags), 30)
+
+	for _, tag := range tags {
+		when, err := r.RefTime(tag)
+		if err != nil {
+			t.Error(id, ": cannot get the time of", tag.Name, ":", err)
+			continue
+		}
+
+		testhelper.DiffTime(t, id+": "+tag.Name, "time",
+			when.UTC(), expRefTime(tag.Name))
+	}
+}
+
+func TestReadObjects(t *testing.T) {
+	dir := mkGitRepo(t)
+
+	checkRepo(t, "loose objects", dir)
+
+	gittest.Run(t, dir, nil, "gc", "-q", "--aggressive")
+
+	if _, err := os.Stat(filepath.Join(dir, ".git", "packed-refs")); err != nil {
+		t.Error("the refs were not packed:", err)
+	}
+
+	checkRepo(t, "packed objects", dir)
+}
+
+func TestReadCommit(t *testing.T) {
+	dir := mkGitRepo(t)
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal("cannot open the repository:", err)
+	}
+	defer r.Close()
+
+	head := strings.TrimSpace(gittest.Run(t, dir, nil, "rev-parse", "HEAD"))
+
+	c, err := r.ReadCommit(head)
+	if err != nil {
+		t.Fatal("cannot read the commit:", err)
+	}
+
+	testhelper.DiffString(t, "HEAD", "subject", c.Subject(), "commit 19")
+	testhelper.DiffString(t, "HEAD", "message", c.Message, "commit 19\n\nbody 19\n")
+	testhelper.DiffString(t, "HEAD", "author", c.Author.Name, "A U Thor")
+	testhelper.DiffInt(t, "HEAD", "parent count", len(c.Parents), 1)
+
+	_, err = r.ReadTag(head)
+	testhelper.DiffString(t, "HEAD", "ReadTag error",
+		fmt.Sprint(err), "object "+head+" is a commit not a tag")
+}
+
+func TestLoadPacksBadIdx(t *testing.T) {
+	dir := mkGitRepo(t)
+
+	gittest.Run(t, dir, nil, "gc", "-q")
+
+	badIdx := filepath.Join(dir, ".git", "objects", "pack", "pack-zzz.idx")
+	if err := os.WriteFile(badIdx, []byte("not an index"), 0o600); err != nil {
+		t.Fatal("cannot write", badIdx, ":", err)
+	}
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal("unexpected error opening the repository:", err)
+	}
+	defer r.Close()
+
+	for range 2 {
+		if err := r.loadPacks(); err == nil {
+			t.Fatal("the bad pack index should have been reported")
+		}
+
+		testhelper.DiffInt(t, "bad idx", "packs", len(r.packs), 0)
+	}
+}
@@ -0,0 +1,408 @@
+package gitrepo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// These are the types of the entries in a pack file
+const (
+	packObjCommit   = 1
+	packObjTree     = 2
+	packObjBlob     = 3
+	packObjTag      = 4
+	packObjOfsDelta = 6
+	packObjRefDelta = 7
+)
+
+var packObjTypes = map[byte]string{
+	packObjCommit: ObjCommit,
+	packObjTree:   ObjTree,
+	packObjBlob:   ObjBlob,
+	packObjTag:    ObjTag,
+}
+
+var packIdxMagic = []byte{0xff, 't', 'O', 'c'}
+
+const (
+	packIdxVersion = 2
+	fanoutLen      = 256
+	largeOffsetBit = 0x80000000
+)
+
+// pack records the index of a pack file and the open pack file
+type pack struct {
+	name    string
+	f       *os.File
+	hashes  []byte
+	offsets []uint64
+}
+
+// count returns the number of objects in the pack
+func (p *pack) count() int {
+	return len(p.offsets)
+}
+
+// find returns the offset in the pack file of the object with the given
+// hash and true if it is in the pack, false otherwise
+func (p *pack) find(hash []byte) (uint64, bool) {
+	n := p.count()
+	i := sort.Search(n, func(i int) bool {
+		return bytes.Compare(p.hashes[i*hashLen:(i+1)*hashLen], hash) >= 0
+	})
+
+	if i < n && bytes.Equal(p.hashes[i*hashLen:(i+1)*hashLen], hash) {
+		return p.offsets[i], true
+	}
+
+	return 0, false
+}
+
+// readPackIdx reads the (version 2) pack index file and returns the
+// corresponding pack with the pack file opened ready for reading
+func readPackIdx(idxName string) (*pack, error) {
+	data, err := os.ReadFile(idxName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	const hdrLen = 8
+
+	if len(data) < hdrLen+fanoutLen*4 || !bytes.Equal(data[:4], packIdxMagic) {
+		return nil, fmt.Errorf("%s: unsupported pack index format", idxName)
+	}
+
+	if v := binary.BigEndian.Uint32(data[4:hdrLen]); v != packIdxVersion {
+		return nil, fmt.Errorf("%s: unsupported pack index version: %d",
+			idxName, v)
+	}
+
+	n := int(binary.BigEndian.Uint32(data[hdrLen+(fanoutLen-1)*4:]))
+	hashStart := hdrLen + fanoutLen*4
+	crcStart := hashStart + n*hashLen
+	offStart := crcStart + n*4
+	largeStart := offStart + n*4
+
+	if len(data) < largeStart {
+		return nil, fmt.Errorf("%s: the pack index is truncated", idxName)
+	}
+
+	p := &pack{
+		hashes:  data[hashStart:crcStart],
+		offsets: make([]uint64, n),
+	}
+
+	for i := range n {
+		off := binary.BigEndian.Uint32(data[offStart+i*4:])
+		if off&largeOffsetBit == 0 {
+			p.offsets[i] = uint64(off)
+			continue
+		}
+
+		largeIdx := largeStart + int(off&^largeOffsetBit)*8
+		if len(data) < largeIdx+8 {
+			return nil, fmt.Errorf("%s: the pack index is truncated", idxName)
+		}
+
+		p.offsets[i] = binary.BigEndian.Uint64(data[largeIdx:])
+	}
+
+	p.name = idxName[:len(idxName)-len(".idx")] + ".pack"
+
+	p.f, err = os.Open(p.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// closePacks closes the pack files
+func closePacks(packs []*pack) error {
+	var errs []error
+
+	for _, p := range packs {
+		errs = append(errs, p.f.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// loadPacks reads the pack indexes, if this has not already been done. The
+// packs are only recorded once every pack index has been read.
+func (r *Repo) loadPacks() error {
+	if r.packsLoaded {
+		return nil
+	}
+
+	idxNames, err := filepath.Glob(
+		filepath.Join(r.commonDir, "objects", "pack", "pack-*.idx"))
+	if err != nil {
+		return err
+	}
+
+	packs := make([]*pack, 0, len(idxNames))
+
+	for _, idxName := range idxNames {
+		p, err := readPackIdx(idxName)
+		if err != nil {
+			_ = closePacks(packs)
+			return err
+		}
+
+		packs = append(packs, p)
+	}
+
+	r.packs = packs
+	r.packsLoaded = true
+
+	return nil
+}
+
+// readPackedObject finds the object in the pack files and returns its type
+// and content.
+func (r *Repo) readPackedObject(hash string) (string, []byte, error) {
+	if err := r.loadPacks(); err != nil {
+		return "", nil, err
+	}
+
+	h, err := hex.DecodeString(hash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range r.packs {
+		if offset, ok := p.find(h); ok {
+			return r.readPackEntry(p, offset)
+		}
+	}
+
+	return "", nil, fmt.Errorf("object %s: %w", hash, os.ErrNotExist)
+}
+
+// readPackEntry reads the entry at the offset in the pack, resolving any
+// deltas, and returns the object type and content
+func (r *Repo) readPackEntry(p *pack, offset uint64) (string, []byte, error) {
+	if offset > math.MaxInt64 {
+		return "", nil, fmt.Errorf("%s: bad offset: %d", p.name, offset)
+	}
+
+	br := bufio.NewReader(
+		io.NewSectionReader(p.f, int64(offset), math.MaxInt64-int64(offset)))
+
+	c, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	entryType := (c >> 4) & 0x7 //nolint:mnd
+	size := uint64(c & 0xf)     //nolint:mnd
+
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+
+		size |= uint64(c&0x7f) << shift
+	}
+
+	var baseType string
+
+	var base []byte
+
+	switch entryType {
+	case packObjOfsDelta:
+		baseOffset, err := readOfsDeltaOffset(br, offset)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", p.name, err)
+		}
+
+		baseType, base, err = r.readPackEntry(p, baseOffset)
+		if err != nil {
+			return "", nil, err
+		}
+	case packObjRefDelta:
+		baseHash := make([]byte, hashLen)
+		if _, err := io.ReadFull(br, baseHash); err != nil {
+			return "", nil, err
+		}
+
+		baseType, base, err = r.ReadObject(hex.EncodeToString(baseHash))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	data, err := inflate(br, size)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: offset %d: %w", p.name, offset, err)
+	}
+
+	if base != nil {
+		data, err = applyDelta(base, data)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: offset %d: %w", p.name, offset, err)
+		}
+
+		return baseType, data, nil
+	}
+
+	objType, ok := packObjTypes[entryType]
+	if !ok {
+		return "", nil, fmt.Errorf("%s: offset %d: bad object type: %d",
+			p.name, offset, entryType)
+	}
+
+	return objType, data, nil
+}
+
+// readOfsDeltaOffset reads the encoded distance back to the base object of
+// an offset delta and returns the offset of the base object
+func readOfsDeltaOffset(br *bufio.Reader, offset uint64) (uint64, error) {
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	dist := uint64(c & 0x7f)
+	for c&0x80 != 0 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, err
+		}
+
+		dist = ((dist + 1) << 7) | uint64(c&0x7f)
+	}
+
+	if dist == 0 || dist > offset {
+		return 0, fmt.Errorf("bad delta base distance: %d", dist)
+	}
+
+	return offset - dist, nil
+}
+
+// inflate decompresses the data read from r and checks that it has the
+// expected size
+func inflate(r io.Reader, size uint64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(data)) != size {
+		return nil, fmt.Errorf("bad object size: %d (expected %d)",
+			len(data), size)
+	}
+
+	return data, nil
+}
+
+// deltaSize reads a size from the header of a delta and returns it with
+// the remainder of the delta
+func deltaSize(delta []byte) (int, []byte, error) {
+	size := 0
+
+	for i, c := range delta {
+		if i*7 > 56 { //nolint:mnd
+			break
+		}
+
+		size |= int(c&0x7f) << (i * 7)
+		if c&0x80 == 0 {
+			return size, delta[i+1:], nil
+		}
+	}
+
+	return 0, nil, errors.New("bad delta header")
+}
+
+// applyDelta applies the delta to the base and returns the result
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcSize != len(base) {
+		return nil, fmt.Errorf("bad delta: the base size is %d (expected %d)",
+			len(base), srcSize)
+	}
+
+	tgtSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	errTruncated := errors.New("bad delta: it is truncated")
+	out := make([]byte, 0, tgtSize)
+
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			var off, n int
+
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+
+				if len(delta) == 0 {
+					return nil, errTruncated
+				}
+
+				if i < 4 { //nolint:mnd
+					off |= int(delta[0]) << (8 * i)
+				} else {
+					n |= int(delta[0]) << (8 * (i - 4))
+				}
+
+				delta = delta[1:]
+			}
+
+			if n == 0 {
+				n = 0x10000
+			}
+
+			if off+n > len(base) {
+				return nil, errors.New("bad delta: the copy is out of range")
+			}
+
+			out = append(out, base[off:off+n]...)
+		case op != 0:
+			n := int(op)
+			if n > len(delta) {
+				return nil, errTruncated
+			}
+
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+		default:
+			return nil, errors.New("bad delta: unexpected zero opcode")
+		}
+	}
+
+	if len(out) != tgtSize {
+		return nil, fmt.Errorf("bad delta: the result size is %d (expected %d)",
+			len(out), tgtSize)
+	}
+
+	return out, nil
+}
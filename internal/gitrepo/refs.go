@@ -0,0 +1,249 @@
+package gitrepo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const (
+	refsPrefix      = "refs/"
+	tagsPrefix      = "refs/tags/"
+	headsPrefix     = "refs/heads/"
+	remotesPrefix   = "refs/remotes/"
+	headName        = "HEAD"
+	packedRefsFile  = "packed-refs"
+	symbolicRefMark = "ref: "
+)
+
+// Ref records the name of a reference and the object it refers to
+type Ref struct {
+	// Name is the full name of the reference, such as refs/tags/v1.2.3
+	Name string
+	// Hash is the hex-encoded ID of the object the reference refers to
+	Hash string
+}
+
+// ShortName returns the name of the reference without the refs/tags/ or
+// refs/heads/ prefix
+func (ref Ref) ShortName() string {
+	for _, pfx := range []string{tagsPrefix, headsPrefix} {
+		if s, ok := strings.CutPrefix(ref.Name, pfx); ok {
+			return s
+		}
+	}
+
+	return ref.Name
+}
+
+// readPackedRefs reads the packed-refs file and adds any references having
+// the given prefix to the map. It is not an error if there is no
+// packed-refs file.
+func (r *Repo) readPackedRefs(prefix string, refs map[string]string) error {
+	fName := filepath.Join(r.commonDir, packedRefsFile)
+
+	f, err := os.Open(fName) //nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer f.Close()
+
+	lineNum := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNum++
+
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		hash, name, ok := strings.Cut(line, " ")
+		if !ok {
+			return fmt.Errorf("%s:%d: bad packed ref: %q", fName, lineNum, line)
+		}
+
+		if strings.HasPrefix(name, prefix) {
+			refs[name] = hash
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readLooseRefs reads the loose reference files under the refs directory
+// and adds any references having the given prefix to the map. Symbolic
+// references are ignored.
+func (r *Repo) readLooseRefs(prefix string, refs map[string]string) error {
+	root := filepath.Join(r.commonDir, filepath.FromSlash(prefix))
+
+	err := filepath.WalkDir(root,
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) && path == root {
+					return filepath.SkipDir
+				}
+
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			content, err := os.ReadFile(path) //nolint:gosec
+			if err != nil {
+				return err
+			}
+
+			hash := strings.TrimSpace(string(content))
+			if strings.HasPrefix(hash, symbolicRefMark) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(r.commonDir, path)
+			if err != nil {
+				return err
+			}
+
+			refs[filepath.ToSlash(rel)] = hash
+
+			return nil
+		})
+
+	return err
+}
+
+// Refs returns the references whose names start with the given prefix,
+// sorted by name. Both the packed-refs file and the loose references are
+// read; a loose reference takes precedence over a packed reference of the
+// same name.
+func (r *Repo) Refs(prefix string) ([]Ref, error) {
+	refMap := map[string]string{}
+
+	if err := r.readPackedRefs(prefix, refMap); err != nil {
+		return nil, err
+	}
+
+	if err := r.readLooseRefs(prefix, refMap); err != nil {
+		return nil, err
+	}
+
+	refs := make([]Ref, 0, len(refMap))
+	for name, hash := range refMap {
+		refs = append(refs, Ref{Name: name, Hash: hash})
+	}
+
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+
+	return refs, nil
+}
+
+// Tags returns the tag references, sorted by name
+func (r *Repo) Tags() ([]Ref, error) {
+	return r.Refs(tagsPrefix)
+}
+
+// maxSymRefDepth is the greatest number of symbolic references that will be
+// followed when reading a reference
+const maxSymRefDepth = 5
+
+// ErrNoSuchRef is returned if a reference or revision cannot be found
+var ErrNoSuchRef = errors.New("no such reference")
+
+// readRef returns the hash of the object the named reference refers to,
+// following any symbolic references. HEAD is read from the git directory;
+// any other reference is read from the loose references or the packed-refs
+// file.
+func (r *Repo) readRef(name string) (string, error) {
+	for range maxSymRefDepth {
+		dir := r.commonDir
+		if name == headName {
+			dir = r.gitDir
+		}
+
+		// a directory of loose references, such as refs/tags/v1 holding
+		// refs/tags/v1/a, is not the reference so it may still be packed
+		content, err := os.ReadFile( //nolint:gosec
+			filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) &&
+			!errors.Is(err, syscall.EISDIR) {
+			return "", err
+		}
+
+		if err == nil {
+			val := strings.TrimSpace(string(content))
+
+			target, ok := strings.CutPrefix(val, symbolicRefMark)
+			if !ok {
+				return val, nil
+			}
+
+			name = target
+
+			continue
+		}
+
+		refs := map[string]string{}
+		if err := r.readPackedRefs(name, refs); err != nil {
+			return "", err
+		}
+
+		if hash, ok := refs[name]; ok {
+			return hash, nil
+		}
+
+		return "", fmt.Errorf("%w: %s", ErrNoSuchRef, name)
+	}
+
+	return "", fmt.Errorf("too many symbolic references from %s", name)
+}
+
+// Resolve returns the hash of the object that the revision refers to. The
+// revision may be a full hex-encoded object ID, HEAD, the full name of a
+// reference or the short name of a tag, branch or remote branch, in that
+// order of preference. Abbreviated object IDs and revision expressions
+// such as HEAD~1 are not supported.
+func (r *Repo) Resolve(rev string) (string, error) {
+	if checkHash(rev) == nil {
+		return rev, nil
+	}
+
+	if rev == "" || strings.Contains(rev, "..") ||
+		filepath.IsAbs(filepath.FromSlash(rev)) {
+		return "", fmt.Errorf("bad revision: %q", rev)
+	}
+
+	names := []string{rev}
+	if rev != headName && !strings.HasPrefix(rev, refsPrefix) {
+		names = []string{
+			tagsPrefix + rev,
+			headsPrefix + rev,
+			remotesPrefix + rev,
+		}
+	}
+
+	for _, name := range names {
+		hash, err := r.readRef(name)
+		if err == nil {
+			return hash, nil
+		}
+
+		if !errors.Is(err, ErrNoSuchRef) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrNoSuchRef, rev)
+}
@@ -0,0 +1,172 @@
+package gitrepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// mkFile creates the file, and any directories needed, with the given
+// content
+func mkFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		t.Fatal("cannot make the directory for", name, ":", err)
+	}
+
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal("cannot write", name, ":", err)
+	}
+}
+
+// mkRepo creates a minimal repository in a temporary directory with the
+// given loose tags and packed refs and returns the directory name
+func mkRepo(t *testing.T, looseTags map[string]string, packed string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, gitDirName)
+	mkFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+
+	for name, hash := range looseTags {
+		mkFile(t, filepath.Join(gitDir, "refs", "tags", name), hash+"\n")
+	}
+
+	if packed != "" {
+		mkFile(t, filepath.Join(gitDir, packedRefsFile), packed)
+	}
+
+	return dir
+}
+
+func TestTags(t *testing.T) {
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+		hash3 = "3333333333333333333333333333333333333333"
+	)
+
+	testCases := []struct {
+		testhelper.ID
+		looseTags map[string]string
+		packed    string
+		expRefs   []Ref
+	}{
+		{
+			ID:      testhelper.MkID("no tags"),
+			expRefs: []Ref{},
+		},
+		{
+			ID: testhelper.MkID("loose tags only"),
+			looseTags: map[string]string{
+				"v1.0.0":     hash1,
+				"api/v0.1.0": hash2,
+			},
+			expRefs: []Ref{
+				{Name: "refs/tags/api/v0.1.0", Hash: hash2},
+				{Name: "refs/tags/v1.0.0", Hash: hash1},
+			},
+		},
+		{
+			ID: testhelper.MkID("packed and loose tags"),
+			looseTags: map[string]string{
+				"v1.0.1": hash3,
+			},
+			packed: "# pack-refs with: peeled fully-peeled sorted\n" +
+				hash1 + " refs/heads/main\n" +
+				hash1 + " refs/tags/v1.0.0\n" +
+				"^" + hash2 + "\n" +
+				hash2 + " refs/tags/v1.0.1\n",
+			expRefs: []Ref{
+				{Name: "refs/tags/v1.0.0", Hash: hash1},
+				{Name: "refs/tags/v1.0.1", Hash: hash3},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := Open(mkRepo(t, tc.looseTags, tc.packed))
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": unexpected error opening the repo:", err)
+		}
+
+		refs, err := r.Tags()
+		if err != nil {
+			t.Error(tc.IDStr(), ": unexpected error reading the tags:", err)
+			continue
+		}
+
+		testhelper.DiffSlice(t, tc.IDStr(), "tags", refs, tc.expRefs)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := mkRepo(t, nil, "")
+
+	linked := t.TempDir()
+	mkFile(t, filepath.Join(linked, gitDirName),
+		"gitdir: "+filepath.Join(dir, gitDirName)+"\n")
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		dir       string
+		expGitDir string
+	}{
+		{
+			ID:        testhelper.MkID("work tree"),
+			dir:       dir,
+			expGitDir: filepath.Join(dir, gitDirName),
+		},
+		{
+			ID:        testhelper.MkID("git dir"),
+			dir:       filepath.Join(dir, gitDirName),
+			expGitDir: filepath.Join(dir, gitDirName),
+		},
+		{
+			ID:        testhelper.MkID(".git file"),
+			dir:       linked,
+			expGitDir: filepath.Join(dir, gitDirName),
+		},
+		{
+			ID:     testhelper.MkID("not a repo"),
+			dir:    t.TempDir(),
+			ExpErr: testhelper.MkExpErr("is not a git repository"),
+		},
+	}
+
+	for _, tc := range testCases {
+		r, err := Open(tc.dir)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "git dir",
+				r.GitDir(), tc.expGitDir)
+		}
+	}
+}
+
+func TestResolveRefDir(t *testing.T) {
+	const (
+		hash1 = "1111111111111111111111111111111111111111"
+		hash2 = "2222222222222222222222222222222222222222"
+	)
+
+	dir := mkRepo(t,
+		map[string]string{"rel/v1.0.0": hash1},
+		hash2+" refs/heads/rel\n")
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal("unexpected error opening the repository:", err)
+	}
+	defer r.Close()
+
+	hash, err := r.Resolve("rel")
+	if err != nil {
+		t.Fatal("unexpected error resolving the revision:", err)
+	}
+
+	testhelper.DiffString(t, "tag directory", "hash", hash, hash2)
+}
@@ -0,0 +1,125 @@
+/*
+Package gitrepo provides read-only access to a local git repository. It
+reads the repository files directly rather than running the git command and
+it never uses the network.
+*/
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const gitDirName = ".git"
+
+// Repo represents a local git repository. It is not safe for concurrent
+// use. It should be closed after use to release any open pack files.
+type Repo struct {
+	// gitDir is the directory holding the repository's HEAD file
+	gitDir string
+	// commonDir is the directory holding the repository's refs and
+	// objects. This differs from the gitDir for a linked worktree.
+	commonDir string
+
+	packsLoaded bool
+	packs       []*pack
+}
+
+// isGitDir returns true if the directory looks like a git directory; that
+// it has a HEAD file
+func isGitDir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "HEAD"))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Open returns a Repo for the repository at dir. The dir may be the top of
+// a working tree (having a .git directory or a .git file pointing to the
+// git directory) or it may be the git directory itself, as for a bare
+// repository.
+func Open(dir string) (*Repo, error) {
+	gitDir, err := findGitDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Repo{gitDir: gitDir, commonDir: gitDir}
+
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err == nil {
+		commonDir := strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+
+		r.commonDir = commonDir
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// findGitDir returns the git directory for the repository at dir
+func findGitDir(dir string) (string, error) {
+	dotGit := filepath.Join(dir, gitDirName)
+
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		if isGitDir(dir) {
+			return dir, nil
+		}
+
+		return "", fmt.Errorf("%q is not a git repository", dir)
+	}
+
+	if info.IsDir() {
+		if !isGitDir(dotGit) {
+			return "", fmt.Errorf("%q is not a git directory", dotGit)
+		}
+
+		return dotGit, nil
+	}
+
+	content, err := os.ReadFile(dotGit) //nolint:gosec
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)),
+		"gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%q does not give the git directory", dotGit)
+	}
+
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	if !isGitDir(gitDir) {
+		return "", fmt.Errorf("%q is not a git directory", gitDir)
+	}
+
+	return gitDir, nil
+}
+
+// GitDir returns the name of the repository's git directory
+func (r *Repo) GitDir() string {
+	return r.gitDir
+}
+
+// Close releases any resources held by the Repo
+func (r *Repo) Close() error {
+	err := closePacks(r.packs)
+
+	r.packs = nil
+	r.packsLoaded = false
+
+	return err
+}
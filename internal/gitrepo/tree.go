@@ -0,0 +1,138 @@
+package gitrepo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// These are the modes of the entries in a tree object
+const (
+	ModeDir        = "40000"
+	ModeFile       = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+	ModeSubmodule  = "160000"
+)
+
+// TreeEntry records a single entry in a tree object
+type TreeEntry struct {
+	Mode string
+	Name string
+	Hash string
+}
+
+// IsDir returns true if the entry is a sub-tree
+func (te TreeEntry) IsDir() bool {
+	return te.Mode == ModeDir
+}
+
+// IsFile returns true if the entry is a regular file
+func (te TreeEntry) IsFile() bool {
+	return te.Mode == ModeFile || te.Mode == ModeExecutable
+}
+
+// ReadTree reads the tree object with the given hash and returns its
+// entries in the order in which they are stored
+func (r *Repo) ReadTree(hash string) ([]TreeEntry, error) {
+	objType, data, err := r.ReadObject(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if objType != ObjTree {
+		return nil, fmt.Errorf("object %s is a %s not a %s",
+			hash, objType, ObjTree)
+	}
+
+	var entries []TreeEntry
+
+	for len(data) > 0 {
+		modeAndName, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < hashLen {
+			return nil, fmt.Errorf("tree %s: bad entry", hash)
+		}
+
+		mode, name, ok := bytes.Cut(modeAndName, []byte{' '})
+		if !ok {
+			return nil, fmt.Errorf("tree %s: bad entry: %q", hash, modeAndName)
+		}
+
+		entries = append(entries, TreeEntry{
+			Mode: string(mode),
+			Name: string(name),
+			Hash: hex.EncodeToString(rest[:hashLen]),
+		})
+
+		data = rest[hashLen:]
+	}
+
+	return entries, nil
+}
+
+// WalkTree calls f for every entry in the tree with the given hash and
+// in all of its sub-trees. The path given is the slash-separated name of
+// the entry relative to the top of the tree. A sub-tree is passed to f
+// before its entries; if f returns fs.SkipDir for a sub-tree then the entries
+// of that sub-tree are not visited. Any other error stops the walk and is
+// returned.
+func (r *Repo) WalkTree(hash string,
+	f func(path string, te TreeEntry) error,
+) error {
+	return r.walkTree(hash, "", f)
+}
+
+// walkTree walks the tree, giving each entry a path starting with the
+// prefix
+func (r *Repo) walkTree(hash, prefix string,
+	f func(path string, te TreeEntry) error,
+) error {
+	entries, err := r.ReadTree(hash)
+	if err != nil {
+		return err
+	}
+
+	for _, te := range entries {
+		p := path.Join(prefix, te.Name)
+
+		err := f(p, te)
+		if te.IsDir() && errors.Is(err, fs.SkipDir) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if te.IsDir() {
+			if err := r.walkTree(te.Hash, p, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RevTree returns the hash of the tree of the commit that the revision
+// refers to. Any annotated tags are followed to the commit.
+func (r *Repo) RevTree(rev string) (string, error) {
+	hash, err := r.Resolve(rev)
+	if err != nil {
+		return "", err
+	}
+
+	if hash, err = r.Peel(hash); err != nil {
+		return "", err
+	}
+
+	c, err := r.ReadCommit(hash)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", rev, err)
+	}
+
+	return c.Tree, nil
+}
@@ -0,0 +1,134 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/semvertools/internal/gittest"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// checkResolve checks that each revision resolves to the same object as
+// git reports
+func checkResolve(t *testing.T, id, dir string) {
+	t.Helper()
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(id, ": cannot open the repository:", err)
+	}
+	defer r.Close()
+
+	for _, rev := range []string{
+		"HEAD", "main", "refs/heads/main", "v1.0.3", "v2.0.4",
+		"refs/tags/v1.0.7",
+	} {
+		expHash := strings.TrimSpace(gittest.Run(t, dir, nil, "rev-parse", rev))
+
+		hash, err := r.Resolve(rev)
+		if err != nil {
+			t.Error(id, ": cannot resolve", rev, ":", err)
+			continue
+		}
+
+		testhelper.DiffString(t, id+": "+rev, "hash", hash, expHash)
+	}
+
+	for _, rev := range []string{"nonesuch", "../HEAD", ""} {
+		_, err := r.Resolve(rev)
+		if err == nil {
+			t.Log(id + ": " + rev)
+			t.Error("\t: an error was expected but none was returned")
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := mkGitRepo(t)
+
+	checkResolve(t, "loose refs", dir)
+
+	gittest.Run(t, dir, nil, "gc", "-q")
+
+	checkResolve(t, "packed refs", dir)
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal("cannot open the repository:", err)
+	}
+	defer r.Close()
+
+	_, err = r.Resolve("nonesuch")
+	if !errors.Is(err, ErrNoSuchRef) {
+		t.Error("the error should be ErrNoSuchRef, got:", err)
+	}
+}
+
+func TestWalkTree(t *testing.T) {
+	dir := mkGitRepo(t)
+	mkFile(t, filepath.Join(dir, "sub", "a.txt"), "a\n")
+	mkFile(t, filepath.Join(dir, "sub", "deeper", "b.txt"), "b\n")
+	mkFile(t, filepath.Join(dir, "skip", "c.txt"), "c\n")
+	gittest.Run(t, dir, nil, "add", ".")
+	gittest.Run(t, dir, nil, "commit", "-q", "-m", "sub-directories")
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal("cannot open the repository:", err)
+	}
+	defer r.Close()
+
+	tree, err := r.RevTree("HEAD")
+	if err != nil {
+		t.Fatal("cannot get the tree of HEAD:", err)
+	}
+
+	files := []string{}
+
+	err = r.WalkTree(tree, func(path string, te TreeEntry) error {
+		if te.IsDir() {
+			if path == "skip" {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		_, data, err := r.ReadObject(te.Hash)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, fmt.Sprintf("%s:%d", path, len(data)))
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal("cannot walk the tree:", err)
+	}
+
+	testhelper.DiffString(t, "WalkTree", "files",
+		strings.Join(files, " "),
+		"file.txt:20300 sub/a.txt:2 sub/deeper/b.txt:2")
+
+	tree, err = r.RevTree("v2.0.0")
+	if err != nil {
+		t.Fatal("cannot get the tree of v2.0.0:", err)
+	}
+
+	entries, err := r.ReadTree(tree)
+	if err != nil {
+		t.Fatal("cannot read the tree of v2.0.0:", err)
+	}
+
+	if testhelper.DiffInt(t, "v2.0.0", "entries", len(entries), 1) {
+		return
+	}
+
+	testhelper.DiffString(t, "v2.0.0", "name", entries[0].Name, "file.txt")
+	testhelper.DiffBool(t, "v2.0.0", "is file", entries[0].IsFile(), true)
+}
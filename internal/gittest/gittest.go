@@ -0,0 +1,46 @@
+/*
+Package gittest provides helper functions for the tests of the semvertools
+packages which need a real git repository. The repositories are made by
+running the git command.
+*/
+package gittest
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Run runs the git command in the directory with the given extra
+// environment and returns the standard output. The configuration of the
+// user is ignored and the author and committer are fixed so that the
+// results do not depend on the environment. The test is failed if the
+// command fails.
+func Run(t *testing.T, dir string, env []string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=A U Thor",
+		"GIT_AUTHOR_EMAIL=author@example.com",
+		"GIT_COMMITTER_NAME=C O Mitter",
+		"GIT_COMMITTER_EMAIL=committer@example.com",
+	)
+	cmd.Env = append(cmd.Env, env...)
+
+	var stderr bytes.Buffer
+
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, stderr.String())
+	}
+
+	return string(out)
+}
@@ -0,0 +1,152 @@
+/*
+Package prid provides functions for working with the pre-release IDs of
+semantic version numbers which are shared between the semvertools commands.
+*/
+package prid
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const digits = "0123456789"
+
+// IncrNumInStr will find the numeric part of the pre-release ID and
+// increment it, replacing it in the string in the same place as it was
+// found. If it is a wholly numeric string then it will be taken as a number
+// and incremented as normal, if it is embedded in a string just that part
+// will be incremented. For instance '123' will be changed to '124' but
+// 'RC012' will be changed to 'RC013'.
+func IncrNumInStr(s string) (string, error) {
+	const (
+		wholeMatch = iota
+		prefixIdx
+		numIdx
+		suffixIdx
+		expectedLen
+	)
+
+	findNumPartRE := regexp.MustCompile("([^0-9]*)([0-9]+)(.*)")
+	parts := findNumPartRE.FindStringSubmatch(s)
+
+	if parts == nil {
+		return s, fmt.Errorf("the string (%q) has no numerical part", s)
+	}
+
+	if parts[wholeMatch] != s {
+		return s,
+			fmt.Errorf("only a part of the pre-release ID (%q) is matched: %q",
+				s, parts[wholeMatch])
+	}
+
+	if len(parts) != expectedLen {
+		return s, errors.New("the pre-release ID ('" +
+			s +
+			"') should be split into a (possibly empty) prefix," +
+			" one or more digits and a (possibly empty) suffix")
+	}
+
+	prefix, numStr, suffix := parts[prefixIdx], parts[numIdx], parts[suffixIdx]
+
+	num, err := strconv.Atoi(numStr)
+	if err != nil {
+		return s, errors.New(
+			"cannot convert the numeric part of the pre-release ID '" +
+				numStr +
+				"' into a number")
+	}
+
+	num++
+
+	if parts[1] == "" && parts[3] == "" {
+		s = strconv.Itoa(num)
+	} else {
+		format := prefix + "%0" + strconv.Itoa(len(numStr)) + "d" + suffix
+		s = fmt.Sprintf(format, num)
+	}
+
+	return s, nil
+}
+
+// Stage returns the name of the pre-release stage given by the pre-release
+// IDs. This is the part of the first ID before any digits so, for instance,
+// the stage of both 'rc.1' and 'rc1' is 'rc'. If there are no IDs or the
+// first ID starts with a digit then the empty string is returned.
+func Stage(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+
+	if idx := strings.IndexAny(ids[0], digits); idx >= 0 {
+		return ids[0][:idx]
+	}
+
+	return ids[0]
+}
+
+// IncrLast returns a copy of the pre-release IDs with the numeric part of
+// the last ID incremented as described for IncrNumInStr. An error is
+// returned if there are no IDs or the last ID has no numeric part.
+func IncrLast(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("there are no pre-release IDs to increment")
+	}
+
+	newIDs := make([]string, len(ids))
+	copy(newIDs, ids)
+
+	lastIdx := len(newIDs) - 1
+
+	newVal, err := IncrNumInStr(newIDs[lastIdx])
+	if err != nil {
+		return ids, err
+	}
+
+	newIDs[lastIdx] = newVal
+
+	return newIDs, nil
+}
+
+// NextStage returns the pre-release IDs for the stage after the stage of
+// the given IDs on the ladder of pre-release stages. The new IDs start at
+// the first pre-release of the stage so 'alpha.3' becomes 'beta.1' and
+// 'alpha3' becomes 'beta1'. If the IDs are at the last stage on the ladder
+// then the next stage is the release and no IDs are returned. An error is
+// returned if there are no IDs (it is already a release) or if the stage is
+// not on the ladder.
+func NextStage(ids, ladder []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("there is no further pre-release stage:" +
+			" it is already a release")
+	}
+
+	stage := Stage(ids)
+
+	rung := slices.Index(ladder, stage)
+	if rung < 0 {
+		return nil, fmt.Errorf("the pre-release stage %q is not one of: %s",
+			stage, strings.Join(ladder, ", "))
+	}
+
+	if rung == len(ladder)-1 {
+		return nil, nil
+	}
+
+	return FirstOfStage(ids, ladder[rung+1]), nil
+}
+
+// FirstOfStage returns the IDs of the first pre-release of the stage,
+// written in the same form as the given IDs. So if the stage of the given
+// IDs is a separate ID, as in 'alpha.3', the first pre-release of the
+// 'beta' stage is 'beta.1' and otherwise, as in 'alpha3', it is 'beta1'.
+func FirstOfStage(ids []string, stage string) []string {
+	if len(ids) > 0 && ids[0] == Stage(ids) {
+		return []string{stage, "1"}
+	}
+
+	return []string{stage + "1"}
+}
@@ -0,0 +1,193 @@
+package prid
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestIncrPRID(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		prid         string
+		pridExpected string
+	}{
+		{
+			ID:           testhelper.MkID("good - whole number"),
+			prid:         "12",
+			pridExpected: "13",
+		},
+		{
+			ID:           testhelper.MkID("good - with prefix"),
+			prid:         "RC12",
+			pridExpected: "RC13",
+		},
+		{
+			ID:           testhelper.MkID("good - with prefix and leading zeros"),
+			prid:         "RC0012",
+			pridExpected: "RC0013",
+		},
+		{
+			ID:           testhelper.MkID("good - with suffix"),
+			prid:         "12-RC",
+			pridExpected: "13-RC",
+		},
+		{
+			ID:           testhelper.MkID("good - with prefix and suffix"),
+			prid:         "RC12-RC",
+			pridExpected: "RC13-RC",
+		},
+		{
+			ID:           testhelper.MkID("bad - no numeric part"),
+			prid:         "RC-RC",
+			pridExpected: "RC-RC",
+			ExpErr: testhelper.MkExpErr(
+				`the string ("RC-RC") has no numerical part`),
+		},
+	}
+
+	for _, tc := range testCases {
+		s, err := IncrNumInStr(tc.prid)
+		if s != tc.pridExpected {
+			t.Log(tc.IDStr())
+			t.Log("\t: expected: '" + tc.pridExpected + "'")
+			t.Log("\t:      got: '" + s + "'")
+			t.Errorf("\t: unexpected value of incremented prid\n")
+		}
+
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestStage(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		ids      []string
+		expStage string
+	}{
+		{
+			ID: testhelper.MkID("no IDs"),
+		},
+		{
+			ID:       testhelper.MkID("separate number"),
+			ids:      []string{"rc", "1"},
+			expStage: "rc",
+		},
+		{
+			ID:       testhelper.MkID("embedded number"),
+			ids:      []string{"beta2"},
+			expStage: "beta",
+		},
+		{
+			ID:  testhelper.MkID("numeric"),
+			ids: []string{"12", "rc"},
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "stage", Stage(tc.ids), tc.expStage)
+	}
+}
+
+func TestIncrLast(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		ids    []string
+		expIDs []string
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			ids:    []string{"rc", "9"},
+			expIDs: []string{"rc", "10"},
+		},
+		{
+			ID:     testhelper.MkID("bad - no numeric part"),
+			ids:    []string{"rc"},
+			expIDs: []string{"rc"},
+			ExpErr: testhelper.MkExpErr(`the string ("rc") has no numerical part`),
+		},
+		{
+			ID:     testhelper.MkID("bad - no IDs"),
+			ExpErr: testhelper.MkExpErr("there are no pre-release IDs"),
+		},
+	}
+
+	for _, tc := range testCases {
+		ids, err := IncrLast(tc.ids)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "IDs", ids, tc.expIDs)
+	}
+}
+
+func TestNextStage(t *testing.T) {
+	ladder := []string{"alpha", "beta", "rc"}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		ids    []string
+		expIDs []string
+	}{
+		{
+			ID:     testhelper.MkID("alpha to beta"),
+			ids:    []string{"alpha", "3"},
+			expIDs: []string{"beta", "1"},
+		},
+		{
+			ID:     testhelper.MkID("compact"),
+			ids:    []string{"alpha3"},
+			expIDs: []string{"beta1"},
+		},
+		{
+			ID:     testhelper.MkID("extra IDs are dropped"),
+			ids:    []string{"beta", "2", "x"},
+			expIDs: []string{"rc", "1"},
+		},
+		{
+			ID:  testhelper.MkID("rc to release"),
+			ids: []string{"rc", "4"},
+		},
+		{
+			ID:     testhelper.MkID("bad - release"),
+			ExpErr: testhelper.MkExpErr("there is no further pre-release stage"),
+		},
+		{
+			ID:  testhelper.MkID("bad - not on the ladder"),
+			ids: []string{"dev", "1"},
+			ExpErr: testhelper.MkExpErr(
+				`the pre-release stage "dev" is not one of: alpha, beta, rc`),
+		},
+	}
+
+	for _, tc := range testCases {
+		ids, err := NextStage(tc.ids, ladder)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "IDs", ids, tc.expIDs)
+	}
+}
+
+func TestFirstOfStage(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		ids    []string
+		expIDs []string
+	}{
+		{
+			ID:     testhelper.MkID("separate"),
+			ids:    []string{"alpha", "3"},
+			expIDs: []string{"beta", "1"},
+		},
+		{
+			ID:     testhelper.MkID("compact"),
+			ids:    []string{"alpha3"},
+			expIDs: []string{"beta1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffStringSlice(t, tc.IDStr(), "IDs",
+			FirstOfStage(tc.ids, "beta"), tc.expIDs)
+	}
+}
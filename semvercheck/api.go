@@ -0,0 +1,619 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gitrepo"
+)
+
+// apiDirSkipped returns true if the directory cannot hold any part of the
+// public API of the module and so its files are not read
+func apiDirSkipped(name string) bool {
+	return name == "testdata" || name == "vendor" || name == "internal" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// apiFileWanted returns true if the file is needed to find the API
+func apiFileWanted(name string) bool {
+	return name == "go.mod" ||
+		(strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go"))
+}
+
+// dirAPIFiles returns the contents of the files in the directory tree which
+// are needed to find the API, keyed by their slash-separated paths
+// relative to the top of the tree
+func dirAPIFiles(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+
+	err := filepath.WalkDir(dir,
+		func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				if p != dir && apiDirSkipped(d.Name()) {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if !d.Type().IsRegular() || !apiFileWanted(d.Name()) {
+				return nil
+			}
+
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+
+			data, err := os.ReadFile(p) //nolint:gosec
+			if err != nil {
+				return err
+			}
+
+			files[filepath.ToSlash(rel)] = data
+
+			return nil
+		})
+
+	return files, err
+}
+
+// gitAPIFiles returns the contents of the files in the tree of the git
+// revision which are needed to find the API, keyed by their paths
+func gitAPIFiles(repoDir, rev string) (map[string][]byte, error) {
+	r, err := gitrepo.Open(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	tree, err := r.RevTree(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+
+	err = r.WalkTree(tree, func(p string, te gitrepo.TreeEntry) error {
+		if te.IsDir() {
+			if apiDirSkipped(te.Name) {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !te.IsFile() || !apiFileWanted(te.Name) {
+			return nil
+		}
+
+		_, data, err := r.ReadObject(te.Hash)
+		if err != nil {
+			return err
+		}
+
+		files[p] = data
+
+		return nil
+	})
+
+	return files, err
+}
+
+// apiFiles returns the files needed to find the API from the source. If
+// the source is a directory the files are read from the directory tree,
+// otherwise the source is taken to be a git revision and the files are
+// read from the tree of that revision.
+func (prog *prog) apiFiles(src string) (map[string][]byte, error) {
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		return dirAPIFiles(src)
+	}
+
+	repoDir := prog.gitRepo
+	if repoDir == "" {
+		repoDir = "."
+	}
+
+	return gitAPIFiles(repoDir, src)
+}
+
+// nestedModule returns true if the file is in a directory below one,
+// other than the top of the tree, which holds a go.mod file. Such a
+// directory is the top of a separate module and is not part of the API.
+func nestedModule(files map[string][]byte, p string) bool {
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		if _, ok := files[dir+"/go.mod"]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apiOf returns the exported API of the Go packages in the files. Each
+// exported item is keyed by its package directory, its kind and its name
+// and the value describes it; if the description changes then the change
+// is incompatible. Packages named main are not part of the API and are
+// ignored.
+func apiOf(files map[string][]byte) (map[string]string, error) {
+	api := map[string]string{}
+	fset := token.NewFileSet()
+
+	for _, p := range slices.Sorted(maps.Keys(files)) {
+		if !strings.HasSuffix(p, ".go") || nestedModule(files, p) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, p, files[p],
+			parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		if f.Name.Name == "main" {
+			continue
+		}
+
+		ac := apiCollector{api: api, pkg: path.Dir(p)}
+		for _, decl := range f.Decls {
+			ac.addDecl(decl)
+		}
+	}
+
+	return api, nil
+}
+
+// apiCollector adds the exported items from the declarations of a package
+// to the API
+type apiCollector struct {
+	api map[string]string
+	pkg string
+}
+
+// add adds the item to the API
+func (ac apiCollector) add(kind, name, desc string) {
+	ac.api[ac.pkg+": "+kind+" "+name] = desc
+}
+
+// addDecl adds any exported items from the declaration to the API
+func (ac apiCollector) addDecl(decl ast.Decl) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		ac.addFunc(d)
+	case *ast.GenDecl:
+		var lastType ast.Expr
+
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				ac.addType(s)
+			case *ast.ValueSpec:
+				// in a const block a spec with no type and no values
+				// repeats the type of the spec before it
+				if d.Tok == token.CONST &&
+					(s.Type != nil || len(s.Values) > 0) {
+					lastType = s.Type
+				}
+
+				typ := s.Type
+				if d.Tok == token.CONST {
+					typ = lastType
+				}
+
+				for _, name := range s.Names {
+					if name.IsExported() {
+						ac.add(d.Tok.String(), name.Name, exprStr(typ))
+					}
+				}
+			}
+		}
+	}
+}
+
+// addFunc adds the function or method to the API if it is exported. A
+// method is only added if its receiver type is also exported.
+func (ac apiCollector) addFunc(d *ast.FuncDecl) {
+	if !d.Name.IsExported() {
+		return
+	}
+
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		ac.add("func", d.Name.Name, funcStr(d.Type))
+		return
+	}
+
+	recv := d.Recv.List[0].Type
+
+	ptr := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		ptr = "*"
+		recv = star.X
+	}
+
+	recvName := baseTypeName(recv)
+	if !ast.IsExported(recvName) {
+		return
+	}
+
+	ac.add("method", recvName+"."+d.Name.Name,
+		"func ("+ptr+recvName+") "+d.Name.Name+
+			strings.TrimPrefix(funcStr(d.Type), "func"))
+}
+
+// addType adds the type to the API if it is exported. The exported fields
+// of a struct are added separately so that adding a field is not taken to
+// be an incompatible change.
+func (ac apiCollector) addType(s *ast.TypeSpec) {
+	if !s.Name.IsExported() {
+		return
+	}
+
+	tParams := ""
+	if s.TypeParams != nil {
+		tParams = "[" + fieldListStr(s.TypeParams, true) + "] "
+	}
+
+	if s.Assign.IsValid() {
+		ac.add("type", s.Name.Name, tParams+"= "+exprStr(s.Type))
+		return
+	}
+
+	st, ok := s.Type.(*ast.StructType)
+	if !ok {
+		ac.add("type", s.Name.Name, tParams+typeStr(s.Type))
+		return
+	}
+
+	ac.add("type", s.Name.Name, tParams+"struct")
+
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			name := baseTypeName(f.Type)
+			if ast.IsExported(name) {
+				ac.add("field", s.Name.Name+"."+name,
+					"embedded "+exprStr(f.Type))
+			}
+
+			continue
+		}
+
+		for _, name := range f.Names {
+			if name.IsExported() {
+				ac.add("field", s.Name.Name+"."+name.Name, exprStr(f.Type))
+			}
+		}
+	}
+}
+
+// baseTypeName returns the name of the type without any pointer, package
+// name or type arguments
+func baseTypeName(e ast.Expr) string {
+	for {
+		switch t := e.(type) {
+		case *ast.StarExpr:
+			e = t.X
+		case *ast.IndexExpr:
+			e = t.X
+		case *ast.IndexListExpr:
+			e = t.X
+		case *ast.SelectorExpr:
+			return t.Sel.Name
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// exprStr returns the expression as a string with any line breaks and
+// repeated spaces removed. A nil expression gives an empty string.
+func exprStr(e ast.Expr) string {
+	if e == nil {
+		return ""
+	}
+
+	var buf bytes.Buffer
+
+	if err := printer.Fprint(&buf, token.NewFileSet(), e); err != nil {
+		return fmt.Sprintf("%#v", e)
+	}
+
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// typeStr returns the type as a string. The methods of an interface are
+// sorted so that reordering them is not taken to be a change.
+func typeStr(e ast.Expr) string {
+	it, ok := e.(*ast.InterfaceType)
+	if !ok {
+		return exprStr(e)
+	}
+
+	elts := []string{}
+
+	for _, f := range it.Methods.List {
+		if ft, ok := f.Type.(*ast.FuncType); ok && len(f.Names) > 0 {
+			elts = append(elts,
+				f.Names[0].Name+strings.TrimPrefix(funcStr(ft), "func"))
+
+			continue
+		}
+
+		elts = append(elts, exprStr(f.Type))
+	}
+
+	slices.Sort(elts)
+
+	return "interface{ " + strings.Join(elts, "; ") + " }"
+}
+
+// funcStr returns the function type as a string without the names of the
+// parameters or results, which do not affect compatibility
+func funcStr(ft *ast.FuncType) string {
+	s := "func"
+
+	if ft.TypeParams != nil {
+		s += "[" + fieldListStr(ft.TypeParams, true) + "]"
+	}
+
+	s += "(" + fieldListStr(ft.Params, false) + ")"
+
+	switch results := fieldListStr(ft.Results, false); {
+	case results == "":
+	case len(ft.Results.List) == 1 && len(ft.Results.List[0].Names) <= 1:
+		s += " " + results
+	default:
+		s += " (" + results + ")"
+	}
+
+	return s
+}
+
+// fieldListStr returns the types of the fields as a comma-separated
+// string. Each type is repeated once for each name. If keepNames is true
+// the names are given as well, as is needed for type parameters.
+func fieldListStr(fl *ast.FieldList, keepNames bool) string {
+	if fl == nil {
+		return ""
+	}
+
+	parts := []string{}
+
+	for _, f := range fl.List {
+		typ := exprStr(f.Type)
+
+		if len(f.Names) == 0 {
+			parts = append(parts, typ)
+			continue
+		}
+
+		for _, name := range f.Names {
+			if keepNames {
+				parts = append(parts, name.Name+" "+typ)
+			} else {
+				parts = append(parts, typ)
+			}
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// apiChanges compares the two APIs and returns the incompatible changes
+// (items removed or changed) and the additions
+func apiChanges(oldAPI, newAPI map[string]string) ([]string, []string) {
+	breaking := []string{}
+	added := []string{}
+
+	for _, k := range slices.Sorted(maps.Keys(oldAPI)) {
+		newDesc, ok := newAPI[k]
+		switch {
+		case !ok:
+			breaking = append(breaking, "removed: "+k)
+		case newDesc != oldAPI[k]:
+			breaking = append(breaking,
+				"changed: "+k+": "+oldAPI[k]+" => "+newDesc)
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(newAPI)) {
+		if _, ok := oldAPI[k]; !ok {
+			added = append(added, "added: "+k)
+		}
+	}
+
+	return breaking, added
+}
+
+// These are the levels of change between two semvers, in increasing order
+const (
+	bumpNone = iota
+	bumpPatch
+	bumpMinor
+	bumpMajor
+)
+
+// bumpNames gives the names of the levels of change
+var bumpNames = []string{"no", "patch", "minor", "major"}
+
+// bumpLevel returns the level of the change from the first semver to the
+// second. If the first is a pre-release of the same version as the second
+// (as in v1.1.0-rc.1 to v1.1.0 or to v1.1.0-rc.2) the change is part of
+// the release of that version and so the level is that of the version
+// itself: a major version if the minor and patch versions are 0, a minor
+// version if the patch version is 0 and otherwise a patch version.
+func bumpLevel(sv1, sv2 *semver.SV) int {
+	if sv1.HasPreRelIDs() && vsnStr(sv1) == vsnStr(sv2) {
+		switch {
+		case sv2.Minor() == 0 && sv2.Patch() == 0:
+			return bumpMajor
+		case sv2.Patch() == 0:
+			return bumpMinor
+		}
+
+		return bumpPatch
+	}
+
+	switch {
+	case sv1.Major() != sv2.Major():
+		return bumpMajor
+	case sv1.Minor() != sv2.Minor():
+		return bumpMinor
+	case sv1.Patch() != sv2.Patch():
+		return bumpPatch
+	}
+
+	return bumpNone
+}
+
+// apiBumpErr returns an error if the change from the first semver to the
+// second is too small for the changes to the API. An incompatible change
+// needs a new major version, or a new minor version if the major version
+// is 0, and an addition needs at least a new minor version.
+func apiBumpErr(sv1, sv2 *semver.SV, breaking, added []string) error {
+	need, why := bumpNone, ""
+
+	switch {
+	case len(breaking) > 0:
+		need = bumpMajor
+		if sv1.Major() == 0 {
+			need = bumpMinor
+		}
+
+		why = fmt.Sprintf("incompatible API changes (%d)", len(breaking))
+	case len(added) > 0:
+		need = bumpMinor
+		why = fmt.Sprintf("API additions (%d)", len(added))
+	}
+
+	got := bumpLevel(sv1, sv2)
+	if got >= need {
+		return nil
+	}
+
+	return fmt.Errorf("%s need a %s version change but there is %s"+
+		" version change",
+		why, bumpNames[need], articled(bumpNames[got]))
+}
+
+// articled returns the name of the level of change with the indefinite
+// article if it needs one
+func articled(name string) string {
+	if name == bumpNames[bumpNone] {
+		return name
+	}
+
+	return "a " + name
+}
+
+// apiNewSrc returns the source of the newer version of the API. If it has
+// not been given this is the work tree of the git repository or, if that
+// is not given, the current directory.
+func (prog *prog) apiNewSrc() string {
+	switch {
+	case prog.apiNew != "":
+		return prog.apiNew
+	case prog.gitRepo != "":
+		return prog.gitRepo
+	}
+
+	return "."
+}
+
+// loadAPIChanges finds the changes between the two versions of the API.
+// This is done once and the results are kept for use with each list.
+func (prog *prog) loadAPIChanges() error {
+	if prog.apiLoaded {
+		return nil
+	}
+
+	apis := []map[string]string{}
+
+	for _, src := range []string{prog.apiOld, prog.apiNewSrc()} {
+		files, err := prog.apiFiles(src)
+		if err != nil {
+			return fmt.Errorf("%q: %w", src, err)
+		}
+
+		api, err := apiOf(files)
+		if err != nil {
+			return fmt.Errorf("%q: %w", src, err)
+		}
+
+		apis = append(apis, api)
+	}
+
+	prog.apiBreaking, prog.apiAdded = apiChanges(apis[0], apis[1])
+	prog.apiLoaded = true
+
+	return nil
+}
+
+// apiFailed records the problem found by the API check against the entry
+// and reports it
+func (prog *prog) apiFailed(e *entry, err error) {
+	e.apiErr = err
+	prog.fail(probAPI)
+	prog.reportSVErr(e, err)
+}
+
+// chkAPI checks that the change between the last two semvers in the list
+// is large enough for the changes between the two versions of the API
+func (prog *prog) chkAPI(el []*entry) {
+	var e1, e2 *entry
+
+	for _, e := range el {
+		if e.sv != nil {
+			e1, e2 = e2, e
+		}
+	}
+
+	if e1 == nil {
+		if e2 != nil {
+			prog.apiFailed(e2,
+				fmt.Errorf("the API check needs at least two %s in the list",
+					semver.Names))
+		}
+
+		return
+	}
+
+	if err := prog.loadAPIChanges(); err != nil {
+		prog.apiFailed(e2, fmt.Errorf("cannot find the API changes: %w", err))
+		return
+	}
+
+	err := apiBumpErr(e1.sv, e2.sv, prog.apiBreaking, prog.apiAdded)
+	if err == nil {
+		return
+	}
+
+	changes := prog.apiAdded
+	if len(prog.apiBreaking) > 0 {
+		changes = prog.apiBreaking
+	}
+
+	prog.recordSeqErr(e1, e2, seqErr{
+		Kind:       probAPI.String(),
+		Msg:        err.Error(),
+		APIChanges: changes,
+		kind:       probAPI,
+	})
+}
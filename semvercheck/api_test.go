@@ -0,0 +1,293 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestAPIChanges(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		oldSrc      string
+		newSrc      string
+		expBreaking []string
+		expAdded    []string
+	}{
+		{
+			ID:     testhelper.MkID("no change"),
+			oldSrc: "func F(a int) {}\nfunc g() {}",
+			newSrc: "func F(b int) {}\nfunc g(x int) {}",
+		},
+		{
+			ID:          testhelper.MkID("removed"),
+			oldSrc:      "func F() {}\nvar V int",
+			newSrc:      "func F() {}",
+			expBreaking: []string{"removed: .: var V"},
+		},
+		{
+			ID:     testhelper.MkID("changed"),
+			oldSrc: "func F(a int) {}",
+			newSrc: "func F(a, b int) {}",
+			expBreaking: []string{
+				"changed: .: func F: func(int) => func(int, int)",
+			},
+		},
+		{
+			ID:       testhelper.MkID("added field"),
+			oldSrc:   "type T struct{ A int }",
+			newSrc:   "type T struct{ A int; B string; c int }",
+			expAdded: []string{"added: .: field T.B"},
+		},
+		{
+			ID:     testhelper.MkID("interface method added"),
+			oldSrc: "type I interface{ A() }",
+			newSrc: "type I interface{ A(); B() }",
+			expBreaking: []string{
+				"changed: .: type I: interface{ A() } =>" +
+					" interface{ A(); B() }",
+			},
+		},
+		{
+			ID:     testhelper.MkID("typed const group"),
+			oldSrc: "type K int\nconst (\n\tA K = iota\n\tB\n)",
+			newSrc: "type K int\nconst (\n\tA = iota\n\tB\n)",
+			expBreaking: []string{
+				"changed: .: const A: K => ",
+				"changed: .: const B: K => ",
+			},
+		},
+		{
+			ID:       testhelper.MkID("generic"),
+			oldSrc:   "func F[T any](t T) T { return t }",
+			newSrc:   "func F[T any](t T) T { return t }\ntype L[T any] []T",
+			expAdded: []string{"added: .: type L"},
+		},
+	}
+
+	for _, tc := range testCases {
+		apis := []map[string]string{}
+
+		for _, src := range []string{tc.oldSrc, tc.newSrc} {
+			api, err := apiOf(map[string][]byte{
+				"p.go": []byte("package p\n" + src + "\n"),
+			})
+			if err != nil {
+				t.Fatal(tc.IDStr(), ": unexpected error:", err)
+			}
+
+			apis = append(apis, api)
+		}
+
+		breaking, added := apiChanges(apis[0], apis[1])
+		testhelper.DiffStringSlice(t, tc.IDStr(), "breaking changes",
+			breaking, tc.expBreaking)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "additions",
+			added, tc.expAdded)
+	}
+}
+
+func TestAPIBumpErr(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		sv1, sv2 string
+		breaking bool
+		added    bool
+	}{
+		{
+			ID:  testhelper.MkID("no change, patch"),
+			sv1: "v1.2.3", sv2: "v1.2.4",
+		},
+		{
+			ID:  testhelper.MkID("added, minor"),
+			sv1: "v1.2.3", sv2: "v1.3.0",
+			added: true,
+		},
+		{
+			ID:  testhelper.MkID("added, patch"),
+			sv1: "v1.2.3", sv2: "v1.2.4",
+			added: true,
+			ExpErr: testhelper.MkExpErr(
+				"API additions (1) need a minor version change" +
+					" but there is a patch version change"),
+		},
+		{
+			ID:  testhelper.MkID("breaking, minor"),
+			sv1: "v1.2.3", sv2: "v1.3.0",
+			breaking: true, added: true,
+			ExpErr: testhelper.MkExpErr(
+				"incompatible API changes (1) need a major version" +
+					" change but there is a minor version change"),
+		},
+		{
+			ID:  testhelper.MkID("breaking, major"),
+			sv1: "v1.2.3", sv2: "v2.0.0",
+			breaking: true,
+		},
+		{
+			ID:  testhelper.MkID("breaking, v0 minor"),
+			sv1: "v0.2.3", sv2: "v0.3.0",
+			breaking: true,
+		},
+		{
+			ID:  testhelper.MkID("breaking, v0 patch"),
+			sv1: "v0.2.3", sv2: "v0.2.4",
+			breaking: true,
+			ExpErr: testhelper.MkExpErr(
+				"incompatible API changes (1) need a minor version" +
+					" change but there is a patch version change"),
+		},
+		{
+			ID:  testhelper.MkID("added, pre-release of a patch"),
+			sv1: "v1.2.3-rc.1", sv2: "v1.2.3",
+			added: true,
+			ExpErr: testhelper.MkExpErr(
+				"but there is a patch version change"),
+		},
+		{
+			ID:  testhelper.MkID("added, pre-release of a minor"),
+			sv1: "v1.3.0-rc.1", sv2: "v1.3.0",
+			added: true,
+		},
+		{
+			ID:  testhelper.MkID("added, pre-releases of a minor"),
+			sv1: "v1.3.0-rc.1", sv2: "v1.3.0-rc.2",
+			added: true,
+		},
+		{
+			ID:  testhelper.MkID("breaking, pre-release of a major"),
+			sv1: "v2.0.0-rc.1", sv2: "v2.0.0",
+			breaking: true,
+		},
+		{
+			ID:  testhelper.MkID("breaking, pre-release of a minor"),
+			sv1: "v1.3.0-rc.1", sv2: "v1.3.0",
+			breaking: true,
+			ExpErr: testhelper.MkExpErr(
+				"incompatible API changes (1) need a major version" +
+					" change but there is a minor version change"),
+		},
+	}
+
+	for _, tc := range testCases {
+		sv1, err := semver.ParseSV(tc.sv1)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot parse sv1:", err)
+		}
+
+		sv2, err := semver.ParseSV(tc.sv2)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot parse sv2:", err)
+		}
+
+		var breaking, added []string
+		if tc.breaking {
+			breaking = []string{"removed: .: func F"}
+		}
+
+		if tc.added {
+			added = []string{"added: .: func G"}
+		}
+
+		err = apiBumpErr(sv1, sv2, breaking, added)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
+
+func TestAPICheck(t *testing.T) {
+	apiDir := filepath.Join(testDataDir, "api")
+
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		format        string
+		apiOld        string
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("minor"),
+			input:         "v1.0.0\nv1.1.0\n",
+			format:        fmtText,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("json"),
+			input:         "v1.0.0\nv1.1.0\n",
+			format:        fmtJSON,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("single"),
+			input:         "v1.0.0\n",
+			format:        fmtJSON,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("bad-source"),
+			input:         "v1.0.0\nv1.1.0\n",
+			format:        fmtJSON,
+			apiOld:        "nonesuch",
+			expExitStatus: 1,
+		},
+		{
+			ID:     testhelper.MkID("major"),
+			input:  "v1.0.0\nbad\nv2.0.0\n",
+			format: fmtText,
+			// the parse error but no API problem
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.format = tc.format
+		prog.apiOld = filepath.Join(apiDir, "old")
+		if tc.apiOld != "" {
+			prog.apiOld = tc.apiOld
+		}
+
+		prog.apiNew = filepath.Join(apiDir, "new")
+
+		stdout, exitStatus := runCheck(t, prog, tc.input, false)
+
+		gfc.Check(t, tc.IDStr(), "api."+tc.Name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			exitStatus, tc.expExitStatus)
+
+		if tc.Name == "major" {
+			continue
+		}
+
+		testhelper.DiffBool(t, tc.IDStr(), "internal ignored",
+			strings.Contains(string(stdout), "internal"), false)
+	}
+}
+
+func TestAPINewSrc(t *testing.T) {
+	apiDir := filepath.Join(testDataDir, "api")
+
+	prog := newProg()
+	testhelper.DiffString(t, "default", "source", prog.apiNewSrc(), ".")
+
+	prog.gitRepo = filepath.Join(apiDir, "new")
+	testhelper.DiffString(t, "git repo", "source",
+		prog.apiNewSrc(), prog.gitRepo)
+
+	prog.apiOld = filepath.Join(apiDir, "old")
+
+	if err := prog.loadAPIChanges(); err != nil {
+		t.Fatal("unexpected error finding the API changes:", err)
+	}
+
+	if len(prog.apiBreaking) == 0 {
+		t.Error("the API changes in the git repo should have been found")
+	}
+
+	prog.apiNew = "other"
+	testhelper.DiffString(t, "given", "source", prog.apiNewSrc(), "other")
+}
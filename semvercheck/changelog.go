@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// changelogHeadingRE matches the heading of a release section in a
+// changelog written in the 'keep a changelog' style, such as:
+//
+//	## [1.2.3] - 2024-05-01
+//	## v1.2.3
+//
+// The version is the first sub-match.
+var changelogHeadingRE = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+
+// changelogUnreleased is the name of the section of a changelog which
+// gathers the changes not yet released. It is not a version and is ignored.
+const changelogUnreleased = "unreleased"
+
+// changelogSV parses the version from a changelog heading. The leading 'v'
+// is optional.
+func changelogSV(vsn string) (*semver.SV, error) {
+	return semver.ParseStrictSV(strings.TrimPrefix(vsn, "v"))
+}
+
+// readChangelog reads the changelog and returns an entry for each version
+// heading in the order in which they appear
+func readChangelog(name string) ([]*entry, error) {
+	f, err := os.Open(name) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	headings := []*entry{}
+	loc := location.New(name)
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		loc.Incr()
+
+		line := scanner.Text()
+
+		m := changelogHeadingRE.FindStringSubmatch(line)
+		if m == nil || strings.EqualFold(m[1], changelogUnreleased) {
+			continue
+		}
+
+		loc.SetContent(line)
+
+		sv, err := changelogSV(m[1])
+		if err != nil {
+			return nil, loc.Errorf("bad version heading: %s", err)
+		}
+
+		e := newEntry(loc, len(headings))
+		e.svStr = m[1]
+		e.sv = sv
+		headings = append(headings, e)
+	}
+
+	return headings, scanner.Err()
+}
+
+// changelogFailed records the changelog error against the entry, reports
+// it and records the problem
+func (prog *prog) changelogFailed(e *entry, err error) {
+	e.changelogErrs = append(e.changelogErrs, err)
+	prog.fail(probChangelog)
+	prog.reportSVErr(e, err)
+}
+
+// chkChangelog checks that each entry has a heading in the changelog. Any
+// headings found are recorded so that those having no matching entry can be
+// reported once all the lists have been checked. Pre-releases need not
+// appear in the changelog and, when checking by module, only the entries
+// for the root module are checked.
+func (prog *prog) chkChangelog(el []*entry) {
+	for _, e := range el {
+		if e.sv == nil || e.module != "" {
+			continue
+		}
+
+		found := false
+
+		for _, h := range prog.changelog {
+			if samePrecedence(e.sv, h.sv) {
+				prog.changelogSeen[h.idx] = true
+				found = true
+			}
+		}
+
+		if !found && !e.sv.HasPreRelIDs() {
+			prog.changelogFailed(e,
+				fmt.Errorf("%s has no entry in the changelog: %s",
+					e.sv, prog.changelogFile))
+		}
+	}
+}
+
+// chkChangelogHeadings checks that the changelog headings are in order,
+// the newest first, and that each has a matching entry. It then reports
+// any headings with problems.
+func (prog *prog) chkChangelogHeadings() {
+	bad := []*entry{}
+
+	for i, h := range prog.changelog {
+		if i > 0 {
+			prev := prog.changelog[i-1]
+
+			switch {
+			case samePrecedence(prev.sv, h.sv):
+				prog.changelogFailed(h,
+					fmt.Errorf("duplicate heading: %s is also at line %d",
+						prev.svStr, prev.loc.Idx()))
+			case semver.Less(prev.sv, h.sv):
+				prog.changelogFailed(h,
+					fmt.Errorf("out of order: %s should come before %s"+
+						" (at line %d)",
+						h.svStr, prev.svStr, prev.loc.Idx()))
+			}
+		}
+
+		if !prog.changelogSeen[h.idx] {
+			prog.changelogFailed(h,
+				fmt.Errorf("there is no %s for the changelog entry: %s",
+					semver.Name, h.svStr))
+		}
+
+		if len(h.changelogErrs) > 0 {
+			bad = append(bad, h)
+		}
+	}
+
+	prog.report(bad)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestReadChangelog(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName       string
+		expHeadings []string
+	}{
+		{
+			ID:          testhelper.MkID("good"),
+			fName:       "CHANGELOG.md",
+			expHeadings: []string{"1.3.0", "1.2.0", "1.0.1", "1.1.0", "v1.0.0"},
+		},
+		{
+			ID:    testhelper.MkID("bad"),
+			fName: "bad.md",
+			ExpErr: testhelper.MkExpErr("bad version heading",
+				"bad.md:3: ## [1.2] - 2024-05-01"),
+		},
+	}
+
+	for _, tc := range testCases {
+		headings, err := readChangelog(
+			filepath.Join(testDataDir, "changelog", tc.fName))
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			if testhelper.DiffInt(t, tc.IDStr(), "headings",
+				len(headings), len(tc.expHeadings)) {
+				continue
+			}
+
+			for i, h := range headings {
+				testhelper.DiffString(t, tc.IDStr(), "heading",
+					h.svStr, tc.expHeadings[i])
+			}
+		}
+	}
+}
+
+func TestChangelog(t *testing.T) {
+	const input = "v1.0.0\n" +
+		"v1.0.1+build.1\n" +
+		"v1.1.0\n" +
+		"v1.2.0-rc.1\n" +
+		"v1.2.0\n" +
+		"v1.2.1\n"
+
+	testCases := []struct {
+		testhelper.ID
+		format        string
+		stream        bool
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("text"),
+			format:        fmtText,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("json"),
+			format:        fmtJSON,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("sarif"),
+			format:        fmtSARIF,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("text-stream"),
+			format:        fmtText,
+			stream:        true,
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.format = tc.format
+		prog.changelogFile = filepath.Join(testDataDir, "changelog",
+			"CHANGELOG.md")
+
+		var err error
+
+		prog.changelog, err = readChangelog(prog.changelogFile)
+		if err != nil {
+			t.Fatal("unexpected error reading the changelog", err)
+		}
+
+		stdout, exitStatus := runCheck(t, prog, input, tc.stream)
+
+		name := tc.format
+		if tc.stream {
+			name = fmtText
+		}
+
+		gfc.Check(t, tc.IDStr(), "changelog."+name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// dateFormats are the formats in which a date may be given in dated input
+var dateFormats = []string{
+	time.RFC3339,
+	time.DateTime,
+	time.DateOnly,
+}
+
+// dateFmtDesc describes the allowed date formats
+const dateFmtDesc = "'" + time.DateOnly + "', '" + time.DateTime +
+	"' or '" + time.RFC3339 + "'"
+
+// parseDate parses the string as a date in any of the allowed formats
+func parseDate(s string) (time.Time, error) {
+	for _, f := range dateFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{},
+		fmt.Errorf("bad date: %q - it should be in the form: %s",
+			s, dateFmtDesc)
+}
+
+// splitDate splits the dated input into the semver string and the date,
+// which are separated by a tab, and records them in the entry
+func (e *entry) splitDate() error {
+	svStr, dateStr, ok := strings.Cut(e.svStr, "\t")
+	if !ok {
+		return errors.New("no date was given after the " + semver.Name)
+	}
+
+	date, err := parseDate(strings.TrimSpace(dateStr))
+	if err != nil {
+		return err
+	}
+
+	e.svStr = svStr
+	e.date = date
+
+	return nil
+}
+
+// dateStr returns the date formatted for reporting or the empty string if
+// the date is not set
+func (e entry) dateStr() string {
+	if e.date.IsZero() {
+		return ""
+	}
+
+	return e.date.Format(time.RFC3339)
+}
+
+// chronoLine returns the name of the release line within which the
+// release dates are checked. This is the minor release line of the
+// semver, whatever release lines the sequence is checked by, so that a
+// patch to an older release line may be released after a newer release.
+func chronoLine(module string, sv *semver.SV) string {
+	return fmt.Sprintf("%s v%d.%d.x", module, sv.Major(), sv.Minor())
+}
+
+// chkChronoList checks the release date of each entry against that of its
+// predecessor in the same release line. Entries without a valid semver are
+// skipped.
+func (prog *prog) chkChronoList(el []*entry) {
+	prev := map[string]*entry{}
+
+	for _, e := range el {
+		if e.sv == nil {
+			continue
+		}
+
+		line := chronoLine(e.module, e.sv)
+		if p := prev[line]; p != nil {
+			prog.chkChronology(p, e)
+		}
+
+		prev[line] = e
+	}
+}
+
+// chkChronology checks that the second entry, if it is greater than the
+// first, was not released before it. Entries without dates are not
+// checked.
+func (prog *prog) chkChronology(e1, e2 *entry) {
+	if e1.date.IsZero() || e2.date.IsZero() {
+		return
+	}
+
+	if !semver.Less(e1.sv, e2.sv) || !e2.date.Before(e1.date) {
+		return
+	}
+
+	prog.reportSeqErr(e1, e2, probOther,
+		fmt.Sprintf("the "+semver.Names+" were released out of order:"+
+			" %s (%s) was released before %s (%s)",
+			e2.sv, e2.dateStr(), e1.sv, e1.dateStr()))
+}
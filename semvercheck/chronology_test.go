@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestChronology(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		seqBy         string
+		expExitStatus int
+	}{
+		{
+			ID: testhelper.MkID("good"),
+			input: "v1.0.0\t2025-01-01\n" +
+				"v1.0.1\t2025-01-01 12:00:00\n" +
+				"v1.1.0\t2025-02-01T00:00:00Z\n",
+			seqBy: seqByAll,
+		},
+		{
+			ID: testhelper.MkID("good-byLine"),
+			input: "v1.4.0\t2025-01-01\n" +
+				"v2.0.0\t2025-02-01\n" +
+				"v1.4.1\t2025-03-01\n",
+			seqBy: seqByMajor,
+		},
+		{
+			ID: testhelper.MkID("maintenance-patch"),
+			input: "v1.4.0\t2025-01-01\n" +
+				"v2.0.0\t2025-02-01\n" +
+				"v1.4.1\t2025-03-01\n" +
+				"v2.0.1\t2025-04-01\n",
+			seqBy: seqByAll,
+			// the sequence is out of order but the dates are not
+			expExitStatus: 1,
+		},
+		{
+			ID: testhelper.MkID("bad-minor-line"),
+			input: "v1.4.0\t2025-01-01\n" +
+				"v2.0.0\t2025-02-01\n" +
+				"v1.4.1\t2024-12-01\n",
+			seqBy:         seqByMajor,
+			expExitStatus: 1,
+		},
+		{
+			ID: testhelper.MkID("bad"),
+			input: "v1.0.0\t2025-01-01\n" +
+				"v1.0.1\t2024-12-31\n" +
+				"v1.0.2\n" +
+				"v1.0.3\tyesterday\n",
+			seqBy:         seqByAll,
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.datedInput = true
+		prog.chkChrono = true
+		prog.seqBy = tc.seqBy
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.seqCheck(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "chronology."+tc.Name, stdout)
+
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
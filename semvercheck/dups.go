@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// samePrecedence returns true if the two semvers have the same precedence,
+// that is, if neither is less than the other. Build IDs are ignored.
+func samePrecedence(sv1, sv2 *semver.SV) bool {
+	return !semver.Less(sv1, sv2) && !semver.Less(sv2, sv1)
+}
+
+// precedenceKey returns a string which is the same for any two semvers
+// having the same precedence; that is the semver without any build IDs
+func precedenceKey(sv *semver.SV) string {
+	return semver.NewSVOrPanic(sv.Major(), sv.Minor(), sv.Patch(),
+		sv.PreRelIDs(), nil).String()
+}
+
+// dupMsg returns the message describing the duplicate semvers
+func dupMsg(sv1, sv2 *semver.SV) string {
+	if semver.Equals(sv1, sv2) {
+		return "duplicate entries"
+	}
+
+	return "duplicate entries: they have the same precedence" +
+		" (build IDs are ignored)"
+}
+
+// dupGroups returns the groups of entries having the same precedence. Only
+// groups with more than one entry are returned. The groups are given in
+// the order in which their first entry appears and the entries in each
+// group are in the order in which they appear.
+func dupGroups(el []*entry) [][]*entry {
+	groups := [][]*entry{}
+	groupIdx := map[string]int{}
+
+	for _, e := range el {
+		if e.sv == nil {
+			continue
+		}
+
+		key := precedenceKey(e.sv)
+
+		idx, ok := groupIdx[key]
+		if !ok {
+			idx = len(groups)
+			groupIdx[key] = idx
+			groups = append(groups, []*entry{})
+		}
+
+		groups[idx] = append(groups[idx], e)
+	}
+
+	dups := [][]*entry{}
+
+	for _, g := range groups {
+		if len(g) > 1 {
+			dups = append(dups, g)
+		}
+	}
+
+	return dups
+}
+
+// dupCheck finds every group of entries having the same precedence and
+// reports them. Each entry after the first in a group has the duplication
+// recorded against it.
+func (prog *prog) dupCheck(el []*entry) {
+	for _, g := range dupGroups(el) {
+		first := g[0]
+
+		for _, e := range g[1:] {
+			prog.addSeqErr(first, e, seqErr{
+				Kind: probDup.String(),
+				Msg:  dupMsg(first.sv, e.sv),
+				kind: probDup,
+			})
+		}
+
+		if prog.format != fmtText {
+			continue
+		}
+
+		w := prog.rptOut()
+
+		fmt.Fprintf(w, "Duplicate %s (build IDs are ignored): %s\n",
+			semver.Names, precedenceKey(first.sv))
+
+		for _, e := range g {
+			fmt.Fprintf(w, "    [%d] %s\n", e.idx, locStr(e.loc))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestDuplicates(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		checkSeq      bool
+		chkDups       bool
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("seq-buildIDs"),
+			input:         "v1.2.2\nv1.2.3+a\nv1.2.3+b\nv1.2.4\n",
+			checkSeq:      true,
+			expExitStatus: 1,
+		},
+		{
+			ID: testhelper.MkID("dups-unsorted"),
+			input: "v1.2.3+a\nv2.0.0\nv1.2.3\nv1.0.0-rc.1\n" +
+				"v2.0.0\nv1.2.3+b\nv1.0.0-rc.1+x\n",
+			chkDups:       true,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("dups-and-seq"),
+			input:         "v1.0.0\nv1.0.1+a\nv1.0.1+b\n",
+			checkSeq:      true,
+			chkDups:       true,
+			expExitStatus: 1,
+		},
+		{
+			ID:       testhelper.MkID("no-dups"),
+			input:    "v1.0.0\nv1.0.1-rc.1\nv1.0.1\n",
+			checkSeq: true,
+			chkDups:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.checkSeq = tc.checkSeq
+		prog.chkDups = tc.chkDups
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.checkList(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "dups."+tc.Name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
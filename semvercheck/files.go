@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// expandFiles expands any glob patterns in the list of file names and
+// returns the resulting list. A name which is not a pattern is returned
+// unchanged, whether or not the file exists, so that any failure to open it
+// will be reported. A pattern which matches no files is an error.
+func expandFiles(names []string) ([]string, error) {
+	files := []string{}
+
+	for _, name := range names {
+		matches, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("bad file name pattern %q: %w", name, err)
+		}
+
+		switch {
+		case len(matches) > 0:
+			files = append(files, matches...)
+		case hasMeta(name):
+			return nil, fmt.Errorf("no files match the pattern %q", name)
+		default:
+			files = append(files, name)
+		}
+	}
+
+	return files, nil
+}
+
+// hasMeta returns true if the name contains any of the characters which
+// are special in a glob pattern
+func hasMeta(name string) bool {
+	for _, c := range name {
+		switch c {
+		case '*', '?', '[', '\\':
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSVsFromFiles will read semver strings from each of the named files,
+// after expanding any glob patterns, and check them. It returns a list of
+// entries for each file; each list is a separate sequence.
+func (prog *prog) getSVsFromFiles(names []string) ([][]*entry, error) {
+	files, err := expandFiles(names)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([][]*entry, 0, len(files))
+
+	for _, fName := range files {
+		f, err := os.Open(fName) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+
+		el, err := prog.getSVsFromReader(f, fName)
+		_ = f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %q: %w", fName, err)
+		}
+
+		lists = append(lists, el)
+	}
+
+	return lists, nil
+}
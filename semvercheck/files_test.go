@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestExpandFiles(t *testing.T) {
+	filesDir := filepath.Join(testDataDir, "files")
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		names    []string
+		expFiles []string
+	}{
+		{
+			ID:    testhelper.MkID("plain names"),
+			names: []string{"a.txt", "nonesuch.txt"},
+			expFiles: []string{
+				"a.txt", "nonesuch.txt",
+			},
+		},
+		{
+			ID:    testhelper.MkID("pattern"),
+			names: []string{filepath.Join(filesDir, "*.txt")},
+			expFiles: []string{
+				filepath.Join(filesDir, "a.txt"),
+				filepath.Join(filesDir, "b.txt"),
+			},
+		},
+		{
+			ID:     testhelper.MkID("pattern matching nothing"),
+			names:  []string{filepath.Join(filesDir, "*.nonesuch")},
+			ExpErr: testhelper.MkExpErr("no files match the pattern"),
+		},
+	}
+
+	for _, tc := range testCases {
+		files, err := expandFiles(tc.names)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffStringSlice(t, tc.IDStr(), "files",
+				files, tc.expFiles)
+		}
+	}
+}
+
+func TestGetSVsFromFiles(t *testing.T) {
+	prog := newProg()
+	prog.files = []string{filepath.Join(testDataDir, "files", "*.txt")}
+
+	fio, err := testhelper.NewStdioFromString("")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	lists, err := prog.getSVsFromFiles(prog.files)
+	if err != nil {
+		t.Fatal("unexpected error reading the files:", err)
+	}
+
+	for _, el := range lists {
+		prog.seqCheck(el)
+	}
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	testhelper.DiffInt(t, "files", "list count", len(lists), 2)
+	gfc.Check(t, "files", "files", stdout)
+	testhelper.DiffInt(t, "files", "exit status", prog.exitStatus, 1)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+var badIDCharsRE = regexp.MustCompile("[^-0-9A-Za-z]+")
+
+// isNumeric returns true if the string is non-empty and wholly made of
+// digits
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// fixIDs corrects the list of IDs, replacing any characters not allowed in
+// an ID with a hyphen, removing any empty IDs and, if stripZeros is true,
+// removing the leading zeros of any wholly numeric IDs.
+func fixIDs(ids []string, stripZeros bool) []string {
+	fixed := []string{}
+
+	for _, id := range ids {
+		id = badIDCharsRE.ReplaceAllString(id, "-")
+		if id == "" {
+			continue
+		}
+
+		if stripZeros && isNumeric(id) {
+			if n, err := strconv.Atoi(id); err == nil {
+				id = strconv.Itoa(n)
+			}
+		}
+
+		fixed = append(fixed, id)
+	}
+
+	return fixed
+}
+
+// suggestFix tries to convert a malformed semver string into a well-formed
+// one. It corrects:
+//
+//   - a missing or upper-case 'v' prefix
+//   - missing minor or patch versions (which are set to zero)
+//   - extra version numbers after the patch version (which are moved into
+//     the build IDs)
+//   - leading zeros in the version numbers or numeric pre-release IDs
+//   - bad characters in the pre-release or build IDs (which are replaced
+//     with a hyphen)
+//   - empty pre-release or build IDs (which are removed)
+//
+// It returns the corrected semver string and true if the string can be
+// corrected, or the empty string and false otherwise.
+func suggestFix(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+
+	s, buildIDs, hasBuildIDs := strings.Cut(s, "+")
+	s, preRelIDs, hasPreRelIDs := strings.Cut(s, "-")
+
+	parts := strings.Split(s, ".")
+	nums := make([]int, 0, len(parts))
+
+	for _, p := range parts {
+		if !isNumeric(p) {
+			return "", false
+		}
+
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return "", false
+		}
+
+		nums = append(nums, n)
+	}
+
+	const vsnParts = 3
+
+	var bIDs []string
+
+	for len(nums) < vsnParts {
+		nums = append(nums, 0)
+	}
+
+	for _, n := range nums[vsnParts:] {
+		bIDs = append(bIDs, strconv.Itoa(n))
+	}
+
+	if hasBuildIDs {
+		bIDs = append(bIDs, strings.Split(buildIDs, ".")...)
+	}
+
+	var prIDs []string
+	if hasPreRelIDs {
+		prIDs = fixIDs(strings.Split(preRelIDs, "."), true)
+	}
+
+	sv, err := semver.NewSV(nums[0], nums[1], nums[2],
+		prIDs, fixIDs(bIDs, false))
+	if err != nil {
+		return "", false
+	}
+
+	return sv.String(), true
+}
+
+// printSkipped prints the lines which were skipped when reading the values
+// as they were read
+func printSkipped(skipped []location.L) {
+	for _, loc := range skipped {
+		line, _ := loc.Content()
+		fmt.Println(line)
+	}
+}
+
+// printFixed prints the values read with any malformed semvers replaced by
+// their suggested correction. Only the text from which the semver was
+// taken is replaced; any other text in the value is preserved, as are any
+// lines skipped when reading the values.
+func (prog *prog) printFixed(el []*entry) {
+	for _, e := range el {
+		printSkipped(e.skipped)
+
+		line := e.input()
+
+		end := e.svOffset + len(e.svStr)
+		if e.suggestion != "" && end <= len(line) &&
+			line[e.svOffset:end] == e.svStr {
+			line = line[:e.svOffset] + e.suggestion + line[end:]
+		}
+
+		fmt.Println(line)
+		printSkipped(e.skippedAfter)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSuggestFix(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		s        string
+		expFix   string
+		expFixed bool
+	}{
+		{
+			ID:       testhelper.MkID("missing patch and v"),
+			s:        "1.2",
+			expFix:   "v1.2.0",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("missing minor and patch"),
+			s:        "v1",
+			expFix:   "v1.0.0",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("extra version number"),
+			s:        "v1.2.3.4",
+			expFix:   "v1.2.3+4",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("extra version number and build IDs"),
+			s:        "v1.2.3.4+b1",
+			expFix:   "v1.2.3+4.b1",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("upper-case V"),
+			s:        "V1.2.3",
+			expFix:   "v1.2.3",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("leading zeros"),
+			s:        "v01.02.003-rc.01",
+			expFix:   "v1.2.3-rc.1",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("bad pre-release ID characters"),
+			s:        "v1.2.3-rc_1",
+			expFix:   "v1.2.3-rc-1",
+			expFixed: true,
+		},
+		{
+			ID:       testhelper.MkID("empty build ID"),
+			s:        "v1.2.3+b..x",
+			expFix:   "v1.2.3+b.x",
+			expFixed: true,
+		},
+		{
+			ID: testhelper.MkID("not a version"),
+			s:  "rubbish",
+		},
+		{
+			ID: testhelper.MkID("non-numeric version"),
+			s:  "v1.x.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		fix, fixed := suggestFix(tc.s)
+		testhelper.DiffBool(t, tc.IDStr(), "fixed", fixed, tc.expFixed)
+		testhelper.DiffString(t, tc.IDStr(), "fix", fix, tc.expFix)
+	}
+}
+
+func TestPrintFixed(t *testing.T) {
+	prog := newProg()
+	prog.fix = true
+	prog.column = 2
+
+	fio, err := testhelper.NewStdioFromString(
+		"1 v1.0.0 first\n2 1.1 second\n3 V1.1.1\n4 rubbish\n")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	prog.printFixed(prog.getSVsFromStdin())
+
+	stdout, stderr, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	gfc.Check(t, "fix", "fix.stdout", stdout)
+	gfc.Check(t, "fix", "fix.stderr", stderr)
+	testhelper.DiffInt(t, "fix", "exit status", prog.exitStatus, 1)
+}
+
+func TestPrintFixedRepeatedToken(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		column    int
+		prefix    string
+		input     string
+		expOutput string
+	}{
+		{
+			ID:        testhelper.MkID("same token in an earlier column"),
+			column:    2,
+			input:     "2 2 notes\n",
+			expOutput: "2 v2.0.0 notes\n",
+		},
+		{
+			ID:        testhelper.MkID("same token with a prefix"),
+			column:    2,
+			prefix:    "rel-",
+			input:     "1.2  rel-1.2\n",
+			expOutput: "1.2  rel-v1.2.0\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.fix = true
+		prog.column = tc.column
+
+		if tc.prefix != "" {
+			prog.prefix = regexp.MustCompile(tc.prefix)
+		}
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Fatal("unexpected error faking IO", err)
+		}
+
+		prog.printFixed(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Fatal("unexpected error retrieving stdout and stderr", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "output",
+			string(stdout), tc.expOutput)
+	}
+}
+
+func TestPrintFixedSkippedLines(t *testing.T) {
+	const input = "# the releases\n" +
+		"v1.0.0\n" +
+		"\n" +
+		"  # the next minor release\n" +
+		"1.1\n" +
+		"# the end\n"
+
+	testCases := []struct {
+		testhelper.ID
+		stream bool
+	}{
+		{
+			ID: testhelper.MkID("list"),
+		},
+		{
+			ID:     testhelper.MkID("stream"),
+			stream: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.fix = true
+		prog.ignoreBlankLines = true
+		prog.commentMarkers = []string{"#"}
+
+		stdout, _ := runCheck(t, prog, input, tc.stream)
+
+		gfc.Check(t, tc.IDStr(), "fix.skipped", stdout)
+	}
+}
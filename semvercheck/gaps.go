@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// maxJumpFor returns the largest amount by which the named part may grow
+// without it being reported as a gap. If no value has been given for the
+// part then the general value is used.
+func (prog *prog) maxJumpFor(partName string) int {
+	partJump := 0
+
+	switch partName {
+	case "major":
+		partJump = prog.maxMajorJump
+	case "minor":
+		partJump = prog.maxMinorJump
+	case "patch":
+		partJump = prog.maxPatchJump
+	}
+
+	if partJump > 0 {
+		return partJump
+	}
+
+	return prog.maxJump
+}
+
+// addAllowedMissing records the semver as one which is allowed to be
+// missing from the sequence. Any pre-release or build IDs are ignored.
+func (prog *prog) addAllowedMissing(s string) error {
+	sv, err := semver.ParseSV(s)
+	if err != nil {
+		return err
+	}
+
+	prog.allowedMissing[vsnStr(sv)] = true
+
+	return nil
+}
+
+// gapAllowed returns true if every semver missing between sv1 and sv2 is
+// allowed to be missing. A semver is allowed to be missing if it is in the
+// list of allowed missing semvers or it has been retracted in the go.mod
+// file of the module; the tag of a retracted version may have been removed.
+func (prog *prog) gapAllowed(module string, sv1, sv2 *semver.SV) bool {
+	gm := prog.goModOf(module)
+	if len(prog.allowedMissing) == 0 && len(gm.retracts) == 0 {
+		return false
+	}
+
+	missing, listed := missingSVs(sv1, sv2)
+	if !listed || len(missing) == 0 {
+		return false
+	}
+
+	for _, m := range missing {
+		if prog.allowedMissing[m] {
+			continue
+		}
+
+		sv, err := semver.ParseSV(m)
+		if err != nil {
+			return false
+		}
+
+		if _, ok := gm.retractedBy(sv); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// maxMissing is the largest number of missing semvers that will be listed
+// for any single gap in the sequence. If there are more than this then no
+// list is given; the message will just report that too many are missing.
+const maxMissing = 1000
+
+// missingSVs returns the semvers which should appear between sv1 and sv2
+// if there are to be no gaps between them. Only the major, minor and patch
+// versions are considered; neither semver's pre-release IDs nor build IDs
+// are taken into account. So, for instance, between v1.2.5 and v1.5.0 the
+// missing semvers are v1.3.0 and v1.4.0. If there would be more than
+// maxMissing semvers in the list then false is returned.
+func missingSVs(sv1, sv2 *semver.SV) ([]string, bool) {
+	maj, minor, patch := sv1.Major(), sv1.Minor(), sv1.Patch()
+
+	chain := []string{}
+	addToChain := func() bool {
+		if len(chain) > maxMissing {
+			return false
+		}
+
+		chain = append(chain,
+			semver.NewSVOrPanic(maj, minor, patch, nil, nil).String())
+
+		return true
+	}
+
+	for maj < sv2.Major() {
+		maj++
+		minor, patch = 0, 0
+
+		if !addToChain() {
+			return nil, false
+		}
+	}
+
+	for minor < sv2.Minor() {
+		minor++
+		patch = 0
+
+		if !addToChain() {
+			return nil, false
+		}
+	}
+
+	for patch < sv2.Patch() {
+		patch++
+
+		if !addToChain() {
+			return nil, false
+		}
+	}
+
+	if len(chain) == 0 {
+		return chain, true
+	}
+
+	// the last semver in the chain has the same major, minor and patch
+	// versions as sv2 and so it is not missing
+	return chain[:len(chain)-1], true
+}
+
+// missingMsg returns a description of the missing semvers suitable for
+// reporting
+func missingMsg(missing []string, listed bool) string {
+	if !listed {
+		return fmt.Sprintf("more than %d %s are missing", maxMissing,
+			semver.Names)
+	}
+
+	s := "missing:"
+	sep := " "
+
+	for _, m := range missing {
+		s += sep + m
+		sep = ", "
+	}
+
+	return s
+}
+
+// printFilledSeq prints the complete expected sequence of semvers. Each valid
+// semver read is printed and any semvers missing from the sequence are
+// printed, in order, in their expected place, followed by a comment
+// showing that they are missing.
+func (prog *prog) printFilledSeq(el []*entry) {
+	if prog.format != fmtText {
+		return
+	}
+
+	for _, e := range el {
+		if e.sv == nil {
+			continue
+		}
+
+		for _, se := range e.seqErrs {
+			if !se.isGap {
+				continue
+			}
+
+			if !se.allListed {
+				fmt.Println("# " + missingMsg(nil, false))
+				continue
+			}
+
+			for _, m := range se.Missing {
+				fmt.Println(m + " # missing")
+			}
+		}
+
+		fmt.Println(e.sv)
+	}
+}
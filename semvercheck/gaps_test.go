@@ -0,0 +1,186 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestMissingSVs(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		sv1, sv2   string
+		expMissing []string
+		expListed  bool
+	}{
+		{
+			ID:         testhelper.MkID("no gap"),
+			sv1:        "v1.2.5",
+			sv2:        "v1.3.0",
+			expMissing: []string{},
+			expListed:  true,
+		},
+		{
+			ID:         testhelper.MkID("minor gap"),
+			sv1:        "v1.2.5",
+			sv2:        "v1.5.0",
+			expMissing: []string{"v1.3.0", "v1.4.0"},
+			expListed:  true,
+		},
+		{
+			ID:         testhelper.MkID("patch gap"),
+			sv1:        "v1.2.5",
+			sv2:        "v1.2.8",
+			expMissing: []string{"v1.2.6", "v1.2.7"},
+			expListed:  true,
+		},
+		{
+			ID:         testhelper.MkID("major gap, non-zero subparts"),
+			sv1:        "v1.2.5",
+			sv2:        "v3.1.2",
+			expMissing: []string{"v2.0.0", "v3.0.0", "v3.1.0", "v3.1.1"},
+			expListed:  true,
+		},
+		{
+			ID:         testhelper.MkID("gap to a pre-release"),
+			sv1:        "v1.2.5",
+			sv2:        "v1.4.0-rc.1",
+			expMissing: []string{"v1.3.0"},
+			expListed:  true,
+		},
+		{
+			ID:        testhelper.MkID("too many"),
+			sv1:       "v1.2.5",
+			sv2:       "v1.2.5000",
+			expListed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		sv1, err := semver.ParseSV(tc.sv1)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot parse sv1:", err)
+		}
+
+		sv2, err := semver.ParseSV(tc.sv2)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot parse sv2:", err)
+		}
+
+		missing, listed := missingSVs(sv1, sv2)
+		testhelper.DiffBool(t, tc.IDStr(), "listed", listed, tc.expListed)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "missing",
+			missing, tc.expMissing)
+	}
+}
+
+func TestPrintFilledSeq(t *testing.T) {
+	prog := newProg()
+
+	fio, err := testhelper.NewStdioFromString(
+		"v1.0.0\nv1.0.2\nv1.3.0-rc.1\nv1.3.0\nv2.1.0\n")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	el := prog.getSVsFromStdin()
+
+	_, _, err = fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	fio, err = testhelper.NewStdioFromString("")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	prog.seqCheck(el)
+	prog.printFilledSeq(el)
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	gfc.Check(t, "fill gaps", "fillGaps", stdout)
+	testhelper.DiffInt(t, "fill gaps", "exit status", prog.exitStatus, 1)
+}
+
+func TestGapTolerance(t *testing.T) {
+	const input = "v1.0.0\nv1.0.3\nv1.2.0\nv1.5.0\nv3.0.0\n"
+
+	testCases := []struct {
+		testhelper.ID
+		maxJump       int
+		maxMinorJump  int
+		allowMissing  []string
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("none"),
+			maxJump:       1,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("maxJump"),
+			maxJump:       3,
+			expExitStatus: 0,
+		},
+		{
+			ID:            testhelper.MkID("maxMinorJump"),
+			maxJump:       1,
+			maxMinorJump:  3,
+			expExitStatus: 1,
+		},
+		{
+			ID:      testhelper.MkID("allowMissing"),
+			maxJump: 1,
+			allowMissing: []string{
+				"v1.0.1", "v1.0.2", "v1.1.0", "v1.3.0", "v2.0.0",
+			},
+			expExitStatus: 1,
+		},
+		{
+			ID:           testhelper.MkID("allowMissing-maxJump"),
+			maxJump:      1,
+			maxMinorJump: 3,
+			allowMissing: []string{
+				"v1.0.1", "v1.0.2", "v2.0.0-rc.1",
+			},
+			expExitStatus: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.maxJump = tc.maxJump
+		prog.maxMinorJump = tc.maxMinorJump
+
+		for _, s := range tc.allowMissing {
+			if err := prog.addAllowedMissing(s); err != nil {
+				t.Fatal(tc.IDStr(), ": unexpected error:", err)
+			}
+		}
+
+		fio, err := testhelper.NewStdioFromString(input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.seqCheck(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "gapTolerance."+tc.Name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gitrepo"
+)
+
+// tagSV returns the module and the semver given by the tag name. The name
+// is taken apart in the same way as a value read from a list so that any
+// column or prefix setting applies and, if the semvers are checked by
+// module, the module path prefix is split off. If no semver can be found
+// a nil semver is returned.
+func (prog *prog) tagSV(name string) (string, *semver.SV) {
+	svStr, _, err := prog.extractSV(name)
+	if err != nil {
+		return "", nil
+	}
+
+	module := ""
+	if prog.byModule {
+		module, svStr = splitModule(svStr)
+	}
+
+	sv, err := semver.ParseSV(svStr)
+	if err != nil {
+		return module, nil
+	}
+
+	return module, sv
+}
+
+// sortTags sorts the tags into semver order. Any tags which cannot be
+// parsed as semvers are sorted by name and placed after the rest. If the
+// semvers are checked by module the tags are sorted by module before being
+// sorted by semver.
+func (prog *prog) sortTags(tags []gitrepo.Ref) {
+	svs := make(map[string]*semver.SV, len(tags))
+	modules := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		modules[tag.Name], svs[tag.Name] = prog.tagSV(tag.ShortName())
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if mi, mj := modules[tags[i].Name], modules[tags[j].Name]; mi != mj {
+			return mi < mj
+		}
+
+		svi, svj := svs[tags[i].Name], svs[tags[j].Name]
+
+		switch {
+		case svi != nil && svj != nil:
+			return semver.Less(svi, svj)
+		case svi != nil:
+			return true
+		case svj != nil:
+			return false
+		}
+
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// getSVsFromGitRepo will read the tags from the local git repository in
+// dir and check them. The tags are sorted into semver order before they
+// are checked. The location of each entry is given by the tag's reference
+// name and it has no line number. If the chronology is to be checked the
+// time of each tag is recorded. It returns a list of entries, one per tag.
+func (prog *prog) getSVsFromGitRepo(dir string) ([]*entry, error) {
+	r, err := gitrepo.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	tags, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	prog.sortTags(tags)
+
+	el := make([]*entry, 0, len(tags))
+
+	for _, tag := range tags {
+		loc := location.New(tag.Name)
+		loc.SetContent(tag.ShortName())
+
+		e := prog.mkRptPrt(loc, len(el))
+		if prog.chkChrono {
+			if e.date, err = r.RefTime(tag); err != nil {
+				return nil, err
+			}
+		}
+
+		el = append(el, e)
+	}
+
+	return el, nil
+}
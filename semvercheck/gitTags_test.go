@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestGetSVsFromGitRepo(t *testing.T) {
+	const hash = "0123456789012345678901234567890123456789"
+
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	tagDir := filepath.Join(gitDir, "refs", "tags")
+
+	if err := os.MkdirAll(tagDir, 0o700); err != nil {
+		t.Fatal("cannot create the tags directory:", err)
+	}
+
+	files := map[string]string{
+		filepath.Join(gitDir, "HEAD"): "ref: refs/heads/main\n",
+		filepath.Join(gitDir, "packed-refs"): hash + " refs/tags/v1.10.0\n" +
+			hash + " refs/tags/v1.0.0\n",
+		filepath.Join(tagDir, "v1.2.0"):    hash + "\n",
+		filepath.Join(tagDir, "v1.1.0"):    hash + "\n",
+		filepath.Join(tagDir, "notSemver"): hash + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal("cannot write", name, ":", err)
+		}
+	}
+
+	prog := newProg()
+
+	fio, err := testhelper.NewStdioFromString("")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	el, err := prog.getSVsFromGitRepo(dir)
+	if err != nil {
+		t.Fatal("unexpected error reading the git tags:", err)
+	}
+
+	prog.seqCheck(el)
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	gfc.Check(t, "git tags", "gitTags", stdout)
+	testhelper.DiffInt(t, "git tags", "exit status", prog.exitStatus, 1)
+}
+
+func TestSortTagsWithPrefix(t *testing.T) {
+	const hash = "0123456789012345678901234567890123456789"
+
+	dir := t.TempDir()
+	tagDir := filepath.Join(dir, ".git", "refs", "tags")
+
+	if err := os.MkdirAll(tagDir, 0o700); err != nil {
+		t.Fatal("cannot create the tags directory:", err)
+	}
+
+	head := filepath.Join(dir, ".git", "HEAD")
+	if err := os.WriteFile(head, []byte("ref: refs/heads/main\n"),
+		0o600); err != nil {
+		t.Fatal("cannot write", head, ":", err)
+	}
+
+	tags := []string{
+		"release-v1.10.0", "release-v1.2.0", "release-v1.0.0",
+		"release-v1.9.0", "release-v1.1.0", "release-v1.3.0",
+		"release-v1.4.0", "release-v1.5.0", "release-v1.6.0",
+		"release-v1.7.0", "release-v1.8.0",
+	}
+	for _, tag := range tags {
+		name := filepath.Join(tagDir, tag)
+		if err := os.WriteFile(name, []byte(hash+"\n"), 0o600); err != nil {
+			t.Fatal("cannot write", name, ":", err)
+		}
+	}
+
+	prog := newProg()
+	prog.prefix = regexp.MustCompile("release-")
+	prog.checkSeq = true
+
+	fio, err := testhelper.NewStdioFromString("")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	el, err := prog.getSVsFromGitRepo(dir)
+	if err != nil {
+		t.Fatal("unexpected error reading the git tags:", err)
+	}
+
+	prog.checkList(el)
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	testhelper.DiffString(t, "prefix", "output", string(stdout), "")
+	testhelper.DiffInt(t, "prefix", "exit status", prog.exitStatus, 0)
+
+	if testhelper.DiffInt(t, "prefix", "entries", len(el), len(tags)) {
+		return
+	}
+
+	testhelper.DiffString(t, "prefix", "last", el[len(el)-1].svStr, "v1.10.0")
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// goModStmt is a single statement from a go.mod file. Statements given in
+// a block, such as:
+//
+//	require (
+//	    a v1.0.0
+//	)
+//
+// are returned separately, each with the verb of the block. Any comment
+// on the same line as the statement is also recorded.
+type goModStmt struct {
+	verb    string
+	args    []string
+	comment string
+	loc     location.L
+}
+
+// retraction records a version or range of versions retracted by a go.mod
+// retract directive
+type retraction struct {
+	low, high *semver.SV
+	rationale string
+	loc       location.L
+}
+
+// goMod records the parts of a go.mod file needed for the checks
+type goMod struct {
+	modPath  string
+	retracts []retraction
+}
+
+// goModComment splits the line into the part before any trailing comment
+// and the text of the comment. A comment starts with '//' outside of any
+// quoted string.
+func goModComment(line string) (string, string) {
+	var quote rune
+
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '`':
+			quote = r
+		case strings.HasPrefix(line[i:], "//"):
+			return line[:i], strings.TrimSpace(line[i+2:])
+		}
+	}
+
+	return line, ""
+}
+
+// goModFields splits the line into white-space separated fields, removing
+// the quotes from any quoted strings. Any comment is returned separately.
+func goModFields(line string) ([]string, string, error) {
+	line, comment := goModComment(line)
+	fields := strings.Fields(line)
+
+	for i, f := range fields {
+		if strings.HasPrefix(f, `"`) || strings.HasPrefix(f, "`") {
+			s, err := strconv.Unquote(f)
+			if err != nil {
+				return nil, "", fmt.Errorf("bad quoted string: %s", f)
+			}
+
+			fields[i] = s
+		}
+	}
+
+	return fields, comment, nil
+}
+
+// parseGoMod reads the go.mod statements from the reader. The locations of
+// the statements are given by the name and the line number.
+func parseGoMod(r io.Reader, name string) ([]goModStmt, error) {
+	stmts := []goModStmt{}
+	blockVerb := ""
+
+	scanner := bufio.NewScanner(r)
+	loc := location.New(name)
+
+	for scanner.Scan() {
+		loc.Incr()
+		loc.SetContent(scanner.Text())
+
+		fields, comment, err := goModFields(scanner.Text())
+		if err != nil {
+			return nil, loc.Error(err.Error())
+		}
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case blockVerb != "":
+			if len(fields) == 1 && fields[0] == ")" {
+				blockVerb = ""
+				continue
+			}
+
+			stmts = append(stmts, goModStmt{
+				verb:    blockVerb,
+				args:    fields,
+				comment: comment,
+				loc:     *loc,
+			})
+		case len(fields) == 2 && fields[1] == "(":
+			blockVerb = fields[0]
+		default:
+			stmts = append(stmts, goModStmt{
+				verb:    fields[0],
+				args:    fields[1:],
+				comment: comment,
+				loc:     *loc,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if blockVerb != "" {
+		return nil, fmt.Errorf("%s: the %q block is not closed",
+			name, blockVerb)
+	}
+
+	return stmts, nil
+}
+
+// readGoMod reads the named go.mod file and returns the details needed for
+// the checks
+func readGoMod(name string) (goMod, error) {
+	f, err := os.Open(name) //nolint:gosec
+	if err != nil {
+		return goMod{}, err
+	}
+	defer f.Close()
+
+	stmts, err := parseGoMod(f, name)
+	if err != nil {
+		return goMod{}, err
+	}
+
+	gm := goMod{}
+
+	for _, s := range stmts {
+		if s.verb == "retract" {
+			r, err := parseRetraction(s)
+			if err != nil {
+				return goMod{}, err
+			}
+
+			gm.retracts = append(gm.retracts, r)
+
+			continue
+		}
+
+		if s.verb != "module" {
+			continue
+		}
+
+		if gm.modPath != "" {
+			return goMod{},
+				s.loc.Error("there is more than one module directive")
+		}
+
+		if len(s.args) != 1 {
+			return goMod{}, s.loc.Error("the module directive should" +
+				" have a single module path")
+		}
+
+		gm.modPath = s.args[0]
+	}
+
+	if gm.modPath == "" {
+		return goMod{}, fmt.Errorf("%s: there is no module directive", name)
+	}
+
+	return gm, nil
+}
+
+// parseRetraction converts the arguments of a retract statement into a
+// retraction. The arguments are either a single version or a closed
+// interval of versions given as '[low, high]'.
+func parseRetraction(s goModStmt) (retraction, error) {
+	r := retraction{rationale: s.comment, loc: s.loc}
+	arg := strings.Join(s.args, " ")
+
+	lowStr, highStr := arg, arg
+
+	if strings.HasPrefix(arg, "[") {
+		interval, ok := strings.CutSuffix(arg[1:], "]")
+		if !ok {
+			return r, s.loc.Errorf("bad retracted interval: %q", arg)
+		}
+
+		lowStr, highStr, ok = strings.Cut(interval, ",")
+		if !ok {
+			return r, s.loc.Errorf("bad retracted interval: %q"+
+				" - it should be '[low, high]'", arg)
+		}
+	}
+
+	var err error
+
+	if r.low, err = semver.ParseSV(strings.TrimSpace(lowStr)); err != nil {
+		return r, s.loc.Errorf("bad retracted version: %s", err)
+	}
+
+	if r.high, err = semver.ParseSV(strings.TrimSpace(highStr)); err != nil {
+		return r, s.loc.Errorf("bad retracted version: %s", err)
+	}
+
+	if semver.Less(r.high, r.low) {
+		return r, s.loc.Errorf("bad retracted interval: %q"+
+			" - the low version is greater than the high", arg)
+	}
+
+	return r, nil
+}
+
+// retractedBy returns the retraction covering the semver and true if there
+// is one. Build IDs are ignored.
+func (gm goMod) retractedBy(sv *semver.SV) (retraction, bool) {
+	for _, r := range gm.retracts {
+		if !semver.Less(sv, r.low) && !semver.Less(r.high, sv) {
+			return r, true
+		}
+	}
+
+	return retraction{}, false
+}
+
+// retractedMsg returns a description of the retraction suitable for
+// reporting
+func (r retraction) retractedMsg() string {
+	msg := fmt.Sprintf("retracted at %s:%d", r.loc.Source(), r.loc.Idx())
+	if r.rationale != "" {
+		msg += " (" + r.rationale + ")"
+	}
+
+	return msg
+}
+
+// chkRetractions marks each entry whose semver has been retracted. If the
+// greatest semver has been retracted a warning is given; the go command
+// will not select a retracted version as the latest.
+func (prog *prog) chkRetractions(el []*entry) {
+	var highest *entry
+
+	for _, e := range el {
+		highest = prog.markRetracted(e, highest)
+	}
+
+	prog.warnIfRetracted(highest)
+}
+
+// markRetracted marks the entry if its semver has been retracted. It
+// returns whichever of the entry and the highest entry so far has the
+// greater semver.
+func (prog *prog) markRetracted(e, highest *entry) *entry {
+	if e.sv == nil {
+		return highest
+	}
+
+	if _, ok := prog.goModOf(e.module).retractedBy(e.sv); ok {
+		e.retracted = true
+	}
+
+	if highest == nil || semver.Less(highest.sv, e.sv) {
+		return e
+	}
+
+	return highest
+}
+
+// warnIfRetracted gives a warning if the semver of the highest entry has
+// been retracted. It returns true if a warning was given.
+func (prog *prog) warnIfRetracted(highest *entry) bool {
+	if highest == nil {
+		return false
+	}
+
+	r, ok := prog.goModOf(highest.module).retractedBy(highest.sv)
+	if !ok {
+		return false
+	}
+
+	prog.reportWarning(highest,
+		fmt.Sprintf("the highest %s (%s) is %s",
+			semver.Name, highest.sv, r.retractedMsg()))
+
+	return true
+}
+
+// pathMajor returns the major version suffix of the module path and the
+// major version that it requires. The suffix is empty if the path has no
+// major version suffix, in which case the major version must be 0 or 1 and
+// the returned major version is -1. The special 'gopkg.in' paths, which end
+// in '.vN', require exactly that major version.
+func pathMajor(modPath string) (string, int) {
+	if strings.HasPrefix(modPath, "gopkg.in/") {
+		i := strings.LastIndex(modPath, ".v")
+		if i >= 0 {
+			if n, ok := majorNum(modPath[i+2:]); ok {
+				return modPath[i:], n
+			}
+		}
+
+		return "", -1
+	}
+
+	i := strings.LastIndex(modPath, "/v")
+	if i >= 0 {
+		if n, ok := majorNum(modPath[i+2:]); ok && n >= 2 {
+			return modPath[i:], n
+		}
+	}
+
+	return "", -1
+}
+
+// majorNum converts the string into a major version number, returning
+// false if it is not a well-formed number
+func majorNum(s string) (int, bool) {
+	if !isNumeric(s) || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s)
+
+	return n, err == nil
+}
+
+// modPathErr returns an error if the semver cannot be a version of the
+// module with the given path, nil otherwise
+func modPathErr(sv *semver.SV, modPath string) error {
+	suffix, reqMajor := pathMajor(modPath)
+	major := sv.Major()
+
+	switch {
+	case strings.HasPrefix(suffix, ".v"):
+		if major != reqMajor {
+			return fmt.Errorf("the module path %q requires a v%d %s",
+				modPath, reqMajor, semver.Name)
+		}
+	case suffix == "":
+		if major > 1 {
+			return fmt.Errorf("the module path %q has no major version"+
+				" suffix but a v%d %s needs it to end in \"/v%d\"",
+				modPath, major, semver.Name, major)
+		}
+	case major != reqMajor:
+		if major > 1 {
+			return fmt.Errorf("the module path %q ends in %q"+
+				" but a v%d %s needs it to end in \"/v%d\"",
+				modPath, suffix, major, semver.Name, major)
+		}
+
+		return fmt.Errorf("the module path %q ends in %q"+
+			" but a v%d %s needs it to have no major version suffix",
+			modPath, suffix, major, semver.Name)
+	}
+
+	return nil
+}
+
+// chkModPath checks each entry against the path of its module and reports
+// any which cannot be versions of the module
+func (prog *prog) chkModPath(el []*entry) {
+	for _, e := range el {
+		modPath := prog.goModOf(e.module).modPath
+		if e.sv == nil || modPath == "" {
+			continue
+		}
+
+		if err := modPathErr(e.sv, modPath); err != nil {
+			e.modErr = err
+			prog.fail(probOther)
+			prog.reportSVErr(e, err)
+		}
+	}
+}
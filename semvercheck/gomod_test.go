@@ -0,0 +1,197 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestReadGoMod(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName      string
+		expModPath string
+		expRetract int
+	}{
+		{
+			ID:         testhelper.MkID("good"),
+			fName:      "testdata/gomod/v2.mod",
+			expModPath: "example.com/mod/v2",
+		},
+		{
+			ID:         testhelper.MkID("retractions"),
+			fName:      "testdata/gomod/retract.mod",
+			expModPath: "example.com/mod",
+			expRetract: 3,
+		},
+		{
+			ID:    testhelper.MkID("bad retraction"),
+			fName: "testdata/gomod/badRetract.mod",
+			ExpErr: testhelper.MkExpErr(
+				`bad retracted interval: "[v1.3.0, v1.2.0]"`+
+					" - the low version is greater than the high",
+				"testdata/gomod/badRetract.mod:3"),
+		},
+		{
+			ID:    testhelper.MkID("no module directive"),
+			fName: "testdata/gomod/noModule.mod",
+			ExpErr: testhelper.MkExpErr(
+				"testdata/gomod/noModule.mod: there is no module directive"),
+		},
+		{
+			ID:    testhelper.MkID("unclosed block"),
+			fName: "testdata/gomod/unclosed.mod",
+			ExpErr: testhelper.MkExpErr(
+				`the "require" block is not closed`),
+		},
+		{
+			ID:     testhelper.MkID("no such file"),
+			fName:  "testdata/gomod/nonesuch.mod",
+			ExpErr: testhelper.MkExpErr("no such file or directory"),
+		},
+	}
+
+	for _, tc := range testCases {
+		gm, err := readGoMod(tc.fName)
+		if testhelper.CheckExpErr(t, err, tc) && err == nil {
+			testhelper.DiffString(t, tc.IDStr(), "module path",
+				gm.modPath, tc.expModPath)
+			testhelper.DiffInt(t, tc.IDStr(), "retractions",
+				len(gm.retracts), tc.expRetract)
+		}
+	}
+}
+
+func TestModPathErr(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		sv      string
+		modPath string
+	}{
+		{
+			ID:      testhelper.MkID("v0, no suffix"),
+			sv:      "v0.1.0",
+			modPath: "example.com/mod",
+		},
+		{
+			ID:      testhelper.MkID("v1, no suffix"),
+			sv:      "v1.1.0",
+			modPath: "example.com/mod",
+		},
+		{
+			ID:      testhelper.MkID("v2, no suffix"),
+			sv:      "v2.0.0",
+			modPath: "example.com/mod",
+			ExpErr: testhelper.MkExpErr(
+				`the module path "example.com/mod" has no major version` +
+					` suffix but a v2 ` + semver.Name +
+					` needs it to end in "/v2"`),
+		},
+		{
+			ID:      testhelper.MkID("v2, v2 suffix"),
+			sv:      "v2.0.0",
+			modPath: "example.com/mod/v2",
+		},
+		{
+			ID:      testhelper.MkID("v3, v2 suffix"),
+			sv:      "v3.1.0",
+			modPath: "example.com/mod/v2",
+			ExpErr: testhelper.MkExpErr(
+				`the module path "example.com/mod/v2" ends in "/v2"` +
+					` but a v3 ` + semver.Name + ` needs it to end in "/v3"`),
+		},
+		{
+			ID:      testhelper.MkID("v1, v2 suffix"),
+			sv:      "v1.1.0",
+			modPath: "example.com/mod/v2",
+			ExpErr: testhelper.MkExpErr(
+				`the module path "example.com/mod/v2" ends in "/v2"` +
+					` but a v1 ` + semver.Name +
+					` needs it to have no major version suffix`),
+		},
+		{
+			ID:      testhelper.MkID("v2, not a suffix"),
+			sv:      "v2.0.0",
+			modPath: "example.com/mod/v1",
+			ExpErr: testhelper.MkExpErr(
+				`the module path "example.com/mod/v1" has no major version`),
+		},
+		{
+			ID:      testhelper.MkID("gopkg.in, good"),
+			sv:      "v1.2.0",
+			modPath: "gopkg.in/yaml.v1",
+		},
+		{
+			ID:      testhelper.MkID("gopkg.in, bad"),
+			sv:      "v2.0.0",
+			modPath: "gopkg.in/yaml.v1",
+			ExpErr: testhelper.MkExpErr(
+				`the module path "gopkg.in/yaml.v1" requires a v1 ` +
+					semver.Name),
+		},
+	}
+
+	for _, tc := range testCases {
+		sv, err := semver.ParseSV(tc.sv)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": bad semver: ", err)
+		}
+
+		testhelper.CheckExpErr(t, modPathErr(sv, tc.modPath), tc)
+	}
+}
+
+func TestRetractions(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		expExitStatus int
+	}{
+		{
+			ID: testhelper.MkID("retracted-gaps"),
+			// v1.0.1, v1.2.0 and v1.3.0 are retracted and v1.5.0 is both
+			// retracted and the highest
+			input:         "v1.0.0\nv1.0.2\nv1.1.0\nv1.4.0\nv1.5.0\n",
+			expExitStatus: 0,
+		},
+		{
+			ID:            testhelper.MkID("unretracted-gap"),
+			input:         "v1.0.0\nv1.0.2\nv1.1.0\nv1.4.0\nv1.7.0\n",
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.checkSeq = true
+
+		var err error
+
+		prog.goMod, err = readGoMod("testdata/gomod/retract.mod")
+		if err != nil {
+			t.Fatal("unexpected error reading the go.mod file:", err)
+		}
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.checkList(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "retract."+tc.Name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// skipLine returns true if the line read should be ignored; that is if it
+// is blank and blank lines are being ignored or if it starts with one of
+// the comment markers. Leading white space is ignored.
+func (prog *prog) skipLine(line string) bool {
+	line = strings.TrimSpace(line)
+
+	if line == "" {
+		return prog.ignoreBlankLines
+	}
+
+	for _, marker := range prog.commentMarkers {
+		if strings.HasPrefix(line, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// column returns the n'th (1-based) white-space separated field of the
+// string together with its byte offset in the string
+func column(s string, n int) (string, int, error) {
+	count := 0
+	start := -1
+
+	for i, r := range s + " " {
+		switch {
+		case !unicode.IsSpace(r):
+			if start < 0 {
+				start = i
+			}
+		case start >= 0:
+			count++
+			if count == n {
+				return s[start:i], start, nil
+			}
+
+			start = -1
+		}
+	}
+
+	return s, 0, fmt.Errorf("there is no column %d, there are only %d",
+		n, count)
+}
+
+// extractSV returns the semver string from the value read together with
+// its byte offset in the value. If a column has been given the value is
+// split on white space and the given column is used. Then any text
+// matching the prefix pattern at the start of the string is removed.
+func (prog *prog) extractSV(s string) (string, int, error) {
+	offset := 0
+
+	if prog.column > 0 {
+		var err error
+
+		s, offset, err = column(s, prog.column)
+		if err != nil {
+			return s, 0, err
+		}
+	}
+
+	if prog.prefix != nil {
+		if loc := prog.prefix.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			s = s[loc[1]:]
+			offset += loc[1]
+		}
+	}
+
+	return s, offset, nil
+}
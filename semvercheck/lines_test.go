@@ -0,0 +1,136 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSkipLine(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		line             string
+		commentMarkers   []string
+		ignoreBlankLines bool
+		expSkip          bool
+	}{
+		{
+			ID:   testhelper.MkID("blank - not ignored"),
+			line: "  ",
+		},
+		{
+			ID:               testhelper.MkID("blank - ignored"),
+			line:             "  ",
+			ignoreBlankLines: true,
+			expSkip:          true,
+		},
+		{
+			ID:   testhelper.MkID("comment - no markers"),
+			line: "# comment",
+		},
+		{
+			ID:             testhelper.MkID("comment"),
+			line:           "  // comment",
+			commentMarkers: []string{"#", "//"},
+			expSkip:        true,
+		},
+		{
+			ID:             testhelper.MkID("not a comment"),
+			line:           "v1.2.3 # comment",
+			commentMarkers: []string{"#", "//"},
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.commentMarkers = tc.commentMarkers
+		prog.ignoreBlankLines = tc.ignoreBlankLines
+
+		testhelper.DiffBool(t, tc.IDStr(), "skip",
+			prog.skipLine(tc.line), tc.expSkip)
+	}
+}
+
+func TestExtractSV(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		val    string
+		column int
+		prefix string
+		expSV  string
+	}{
+		{
+			ID:    testhelper.MkID("whole value"),
+			val:   "v1.2.3 2026-01-02",
+			expSV: "v1.2.3 2026-01-02",
+		},
+		{
+			ID:     testhelper.MkID("column"),
+			val:    "  2026-01-02   v1.2.3 release notes",
+			column: 2,
+			expSV:  "v1.2.3",
+		},
+		{
+			ID:     testhelper.MkID("missing column"),
+			val:    "v1.2.3",
+			column: 2,
+			expSV:  "v1.2.3",
+			ExpErr: testhelper.MkExpErr("there is no column 2"),
+		},
+		{
+			ID:     testhelper.MkID("prefix"),
+			val:    "release-v1.2.3",
+			prefix: "[a-z]+-",
+			expSV:  "v1.2.3",
+		},
+		{
+			ID:     testhelper.MkID("prefix not at the start"),
+			val:    "v1.2.3-rc.1",
+			prefix: "-",
+			expSV:  "v1.2.3-rc.1",
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.column = tc.column
+
+		if tc.prefix != "" {
+			prog.prefix = regexp.MustCompile(tc.prefix)
+		}
+
+		s, _, err := prog.extractSV(tc.val)
+		testhelper.CheckExpErr(t, err, tc)
+		testhelper.DiffString(t, tc.IDStr(), "semver", s, tc.expSV)
+	}
+}
+
+func TestGetSVsFromReaderSkipping(t *testing.T) {
+	prog := newProg()
+	prog.commentMarkers = []string{"#"}
+	prog.ignoreBlankLines = true
+	prog.column = 1
+
+	input := "# header\n\nv1.0.0 first\nv1.0.1 second\n# trailer\nv1.0.2\n"
+
+	el, err := prog.getSVsFromReader(strings.NewReader(input), "test")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expLines := []int64{3, 4, 6}
+	if testhelper.DiffInt(t, "skipping", "entry count",
+		len(el), len(expLines)) {
+		return
+	}
+
+	for i, e := range el {
+		testhelper.DiffInt(t, "skipping", "line", e.loc.Idx(), expLines[i])
+		testhelper.DiffInt(t, "skipping", "index", e.idx, i)
+	}
+
+	testhelper.DiffInt(t, "skipping", "exit status", prog.exitStatus, 0)
+}
@@ -3,9 +3,16 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strings"
 
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/filecheck.mod/filecheck"
+	"github.com/nickwells/fileparse.mod/fileparse"
 	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/paction"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/psetter"
 	"github.com/nickwells/semver.mod/v3/semver"
@@ -14,18 +21,101 @@ import (
 
 // Created: Wed Jan 16 22:49:24 2019
 
+const (
+	paramNameChkPreRelSeq = "check-pre-rel-seq"
+	paramNamePreRelLadder = "pre-rel-ladder"
+	paramNameGitRepo      = "git-repo"
+	paramNameFiles        = "files"
+	paramNameFix          = "fix"
+	paramNameFillGaps     = "fill-gaps"
+	paramNameDatedInput   = "dated-input"
+	paramNameChkChrono    = "check-chronology"
+	paramNameGoMod        = "go-mod"
+	paramNamePolicy       = "policy"
+	paramNameAllowMissing = "allow-missing"
+	paramNameStream       = "stream"
+	paramNameChkDups      = "check-dups"
+	paramNameByModule     = "by-module"
+	paramNameModuleRoot   = "module-root"
+	paramNameChangelog    = "changelog"
+	paramNameAPIOld       = "api-old"
+	paramNameAPINew       = "api-new"
+)
+
 // prog holds the parameter values and intermediate results
 type prog struct {
 	checkSeq     bool
+	seqBy        string
+	fillGaps     bool
+	fix          bool
+	chkPreRelSeq bool
+	preRelLadder []string
 	printSV      bool
+	format       string
+	gitRepo      string
+	files        []string
+	datedInput   bool
+	stream       bool
+	chkDups      bool
+
+	byModule          bool
+	moduleRoot        string
+	moduleDirReported map[string]bool
+	moduleGoMods      map[string]goMod
+	moduleSummary     map[string]*moduleStats
+	moduleOrder       []string
+
+	changelogFile string
+	changelog     []*entry
+	changelogSeen map[int]bool
+
+	apiOld      string
+	apiNew      string
+	apiLoaded   bool
+	apiBreaking []string
+	apiAdded    []string
+
+	goModFile    string
+	goMod        goMod
+	policyFile   string
+	policy       policy
+	paramsSet    map[string]bool
+	maxJump      int
+	maxMajorJump int
+	maxMinorJump int
+	maxPatchJump int
+
+	allowMissing   []string
+	allowedMissing map[string]bool
+
+	commentMarkers   []string
+	ignoreBlankLines bool
+	column           int
+	prefix           *regexp.Regexp
+
+	chkChrono    bool
 	semverChecks semverparams.SemverChecks
 
+	sarifResults []sarifResult
+
+	exitMode   string
+	problems   problem
 	exitStatus int
 }
 
 // newProg returns a new Prog instance with any default values set
 func newProg() *prog {
-	return &prog{}
+	return &prog{
+		format:   fmtText,
+		seqBy:    seqByAll,
+		exitMode: exitModeSimple,
+		maxJump:  1,
+
+		allowedMissing: map[string]bool{},
+		changelogSeen:  map[int]bool{},
+		paramsSet:      map[string]bool{},
+		moduleGoMods:   map[string]goMod{},
+	}
 }
 
 func main() {
@@ -34,31 +124,181 @@ func main() {
 
 	ps.Parse()
 
-	var svList []*semver.SV
-	if cmdLineSVs := ps.TrailingParams(); len(cmdLineSVs) > 0 {
-		svList = prog.getSVsFromStrings(cmdLineSVs)
+	if prog.goModFile != "" {
+		var err error
+		prog.goMod, err = readGoMod(prog.goModFile)
+		exitOnErr("cannot read the go.mod file", err)
+	}
+
+	if prog.changelogFile != "" {
+		var err error
+		prog.changelog, err = readChangelog(prog.changelogFile)
+		exitOnErr("cannot read the changelog", err)
+	}
+
+	if prog.policyFile != "" {
+		exitOnErr("cannot read the policy file",
+			prog.readPolicy(prog.policyFile))
+	}
+
+	if prog.stream {
+		prog.streamLists(ps.TrailingParams())
 	} else {
-		svList = prog.getSVsFromStdin()
+		for _, el := range prog.getEntryLists(ps.TrailingParams()) {
+			prog.checkList(el)
+		}
+	}
+
+	prog.finishReport()
+
+	os.Exit(prog.exitStatus)
+}
+
+// checkList performs the checks on the list of entries and reports the
+// results
+func (prog *prog) checkList(el []*entry) {
+	if prog.byModule {
+		prog.chkModuleDirs(el)
+	}
+
+	if prog.chkDups {
+		prog.dupCheck(el)
+	}
+
+	prog.chkModPath(el)
+	prog.chkRetractions(el)
+
+	prog.chkPolicy(el)
+
+	if prog.changelogFile != "" {
+		prog.chkChangelog(el)
 	}
 
 	if prog.checkSeq {
-		prog.seqCheck(svList)
+		prog.seqCheck(el)
 	}
 
-	os.Exit(prog.exitStatus)
+	if prog.apiOld != "" {
+		prog.chkAPI(el)
+	}
+
+	if prog.fillGaps {
+		prog.printFilledSeq(el)
+	}
+
+	if prog.fix {
+		prog.printFixed(el)
+	}
+
+	prog.report(el)
+
+	if prog.byModule {
+		for _, e := range el {
+			prog.addToSummary(e)
+		}
+	}
 }
 
-// seqCheck will compare each entry in the semver list against its
+// exitOnErr reports the error and exits if it is not nil
+func exitOnErr(intro string, err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, intro+":", err)
+		os.Exit(1)
+	}
+}
+
+// getEntryLists reads the semvers from the chosen source and returns the
+// resulting lists of entries; each list is checked as a separate
+// sequence. If the semvers are being checked by module then each list is
+// split into a separate list for each module. On any failure to read the
+// source it reports the problem and exits.
+func (prog *prog) getEntryLists(cmdLineSVs []string) [][]*entry {
+	lists := prog.readEntryLists(cmdLineSVs)
+	if !prog.byModule {
+		return lists
+	}
+
+	moduleLists := [][]*entry{}
+	for _, el := range lists {
+		moduleLists = append(moduleLists, groupByModule(el)...)
+	}
+
+	return moduleLists
+}
+
+// readEntryLists reads the semvers from the chosen source and returns the
+// resulting lists of entries. On any failure to read the source it reports
+// the problem and exits.
+func (prog *prog) readEntryLists(cmdLineSVs []string) [][]*entry {
+	prog.chkSources(cmdLineSVs)
+
+	switch {
+	case prog.gitRepo != "":
+		el, err := prog.getSVsFromGitRepo(prog.gitRepo)
+		exitOnErr("cannot read the git tags", err)
+
+		return [][]*entry{el}
+	case len(prog.files) > 0:
+		lists, err := prog.getSVsFromFiles(prog.files)
+		exitOnErr("cannot read the files", err)
+
+		return lists
+	case len(cmdLineSVs) > 0:
+		return [][]*entry{prog.getSVsFromStrings(cmdLineSVs)}
+	}
+
+	el, err := prog.getSVsFromReader(os.Stdin, "standard input")
+	exitOnErr("cannot read the standard input", err)
+
+	return [][]*entry{el}
+}
+
+// chkSources checks that only one source of semvers has been given. If
+// more than one has been given it reports the problem and exits.
+func (prog *prog) chkSources(cmdLineSVs []string) {
+	if len(cmdLineSVs) > 0 && (prog.gitRepo != "" || len(prog.files) > 0) {
+		fmt.Fprintf(os.Stderr,
+			"%s values cannot be given as well as the %q or %q parameters"+
+				" (the names given with the %q parameter must be"+
+				" separated by commas)\n",
+			semver.ShortName, paramNameGitRepo, paramNameFiles,
+			paramNameFiles)
+		os.Exit(1)
+	}
+}
+
+// seqCheck will split the entries into release lines and check the
+// sequence of each separately. If the semvers are not being grouped by
+// release line then the whole list is checked as a single sequence.
+func (prog *prog) seqCheck(el []*entry) {
+	if prog.seqBy == seqByAll {
+		prog.seqCheckList(el)
+	} else {
+		for _, group := range prog.groupByReleaseLine(el) {
+			prog.seqCheckList(group)
+		}
+	}
+
+	if prog.chkChrono {
+		prog.chkChronoList(el)
+	}
+}
+
+// seqCheckList will compare each entry in the semver list against its
 // predecessor and if it is not one greater than it then it will report an
-// error.
-func (prog *prog) seqCheck(svl []*semver.SV) {
-	var prevSV *semver.SV
-	for i, sv := range svl {
-		if prevSV != nil {
-			prog.chkSequence(prevSV, sv, i)
+// error. Any entries which could not be converted into a semver are skipped.
+func (prog *prog) seqCheckList(el []*entry) {
+	var prev *entry
+	for _, e := range el {
+		if e.sv == nil {
+			continue
 		}
 
-		prevSV = sv
+		if prev != nil {
+			prog.chkSequence(prev, e)
+		}
+
+		prev = e
 	}
 }
 
@@ -71,29 +311,28 @@ func (prog *prog) seqCheck(svl []*semver.SV) {
 //
 // that
 //
-//	p2 == p1 + 1
+//	p2 == p1 + 1 (or, if a larger jump is allowed, p2 <= p1 + max jump)
 //
 //	and all of the p2 subparts are 0
 //
-// If any checks fail it returns a non-nil error and sets the exitStatus to 1.
+// If any checks fail it returns a non-nil error.
 func (prog *prog) chkSVPart(partName string, p1, p2 int, p2subs []int,
-) (err error) {
-	defer func() {
-		if err != nil {
-			prog.exitStatus = 1
-		}
-	}()
-
+) error {
 	if p1 > p2 {
 		return fmt.Errorf("the "+semver.Names+" are out of order:"+
 			" the %s version: %d > %d ", partName, p1, p2)
 	}
 
 	if p1 < p2 {
-		if p2 != p1+1 {
+		if maxJump := prog.maxJumpFor(partName); p2 > p1+maxJump {
+			should := "1"
+			if maxJump > 1 {
+				should = fmt.Sprintf("at most %d", maxJump)
+			}
+
 			return fmt.Errorf("the "+semver.Names+" have gaps:"+
-				" the %s version has grown by %d (should be 1)",
-				partName, p2-p1)
+				" the %s version has grown by %d (should be %s)",
+				partName, p2-p1, should)
 		}
 
 		for _, p := range p2subs {
@@ -109,18 +348,82 @@ func (prog *prog) chkSVPart(partName string, p1, p2 int, p2subs []int,
 	return nil
 }
 
-// reportSeqErr reports an error in the list of IDs
-func (prog *prog) reportSeqErr(sv1, sv2 *semver.SV, idx2 int, msg string) {
-	fmt.Printf("Bad ID list at: [%d] %s, [%d] %s:\n", idx2-1, sv1, idx2, sv2)
-	fmt.Printf("    %s\n", msg)
+// reportSeqErr reports an error in the list of IDs and records it against
+// the second entry
+func (prog *prog) reportSeqErr(e1, e2 *entry, p problem, msg string) {
+	prog.recordSeqErr(e1, e2, seqErr{Kind: p.String(), Msg: msg, kind: p})
+}
+
+// reportGapErr reports a gap in the list of IDs, together with the missing
+// IDs, and records it against the second entry
+func (prog *prog) reportGapErr(e1, e2 *entry, msg string) {
+	missing, listed := missingSVs(e1.sv, e2.sv)
+	prog.recordSeqErr(e1, e2, seqErr{
+		Kind:      probGap.String(),
+		Msg:       msg,
+		Missing:   missing,
+		kind:      probGap,
+		isGap:     true,
+		allListed: listed,
+	})
+}
+
+// recordSeqErr completes the sequence error, records it against the second
+// entry and reports it
+func (prog *prog) recordSeqErr(e1, e2 *entry, se seqErr) {
+	se = prog.addSeqErr(e1, e2, se)
 
-	prog.exitStatus = 1
+	if prog.format == fmtText {
+		w := prog.rptOut()
+
+		if len(prog.files) > 0 {
+			fmt.Fprintf(w, "%s:%d: ", e2.loc.Source(), e2.loc.Idx())
+		}
+
+		if prog.byModule {
+			fmt.Fprintf(w, "Module %s: ", moduleName(e2.module))
+		}
+
+		if se.ReleaseLine != "" {
+			fmt.Fprintf(w, "Bad ID list in release line %s at:",
+				se.ReleaseLine)
+		} else {
+			fmt.Fprint(w, "Bad ID list at:")
+		}
+
+		fmt.Fprintf(w, " [%d] %s, [%d] %s:\n", e1.idx, e1.sv, e2.idx, e2.sv)
+		fmt.Fprintf(w, "    %s\n", se.Msg)
+
+		if se.isGap {
+			fmt.Fprintf(w, "    %s\n", missingMsg(se.Missing, se.allListed))
+		}
+
+		for _, c := range se.APIChanges {
+			fmt.Fprintf(w, "        %s\n", c)
+		}
+	}
+}
+
+// addSeqErr completes the sequence error, records it against the second
+// entry and records the problem. It returns the completed error.
+func (prog *prog) addSeqErr(e1, e2 *entry, se seqErr) seqErr {
+	se.PrevIdx = e1.idx
+	se.Prev = e1.sv.String()
+	se.Idx = e2.idx
+	se.ReleaseLine = prog.releaseLine(e2.sv)
+	se.prevLoc = e1.loc
+	e2.seqErrs = append(e2.seqErrs, se)
+
+	prog.fail(se.kind)
+
+	return se
 }
 
-// chkSequence checks that the two semvers are in order and that sv2 is one
-// ahead of sv1, that either the patch, minor or major numbers are greater by
-// precisely one
-func (prog *prog) chkSequence(sv1, sv2 *semver.SV, idx2 int) {
+// chkSequence checks that the two semvers are in order and that the second
+// is one ahead of the first, that either the patch, minor or major numbers
+// are greater by precisely one
+func (prog *prog) chkSequence(e1, e2 *entry) {
+	sv1, sv2 := e1.sv, e2.sv
 	sv1Parts := []int{sv1.Major(), sv1.Minor(), sv1.Patch()}
 	sv2Parts := []int{sv2.Major(), sv2.Minor(), sv2.Patch()}
 	partNames := []string{"major", "minor", "patch"}
@@ -131,8 +434,18 @@ func (prog *prog) chkSequence(sv1, sv2 *semver.SV, idx2 int) {
 			remainder := sv2Parts[i+1:]
 
 			err := prog.chkSVPart(name, p1, p2, remainder)
-			if err != nil {
-				prog.reportSeqErr(sv1, sv2, idx2, err.Error())
+			if err != nil && !(p1 < p2 && prog.gapAllowed(e1.module, sv1, sv2)) {
+				if p1 < p2 {
+					prog.reportGapErr(e1, e2, err.Error())
+				} else {
+					prog.reportSeqErr(e1, e2, probOrder, err.Error())
+				}
+
+				return
+			}
+
+			if prog.chkPreRelSeq {
+				prog.chkNewVsnPreRel(e1, e2)
 			}
 
 			return
@@ -140,7 +453,7 @@ func (prog *prog) chkSequence(sv1, sv2 *semver.SV, idx2 int) {
 	}
 
 	if semver.Less(sv2, sv1) {
-		prog.reportSeqErr(sv1, sv2, idx2,
+		prog.reportSeqErr(e1, e2, probOrder,
 			"the "+semver.Names+" are out of order:"+
 				" the former is greater than the latter"+
 				" - check the pre-release IDs")
@@ -148,96 +461,178 @@ func (prog *prog) chkSequence(sv1, sv2 *semver.SV, idx2 int) {
 		return
 	}
 
-	if semver.Equals(sv1, sv2) {
-		prog.reportSeqErr(sv1, sv2, idx2, "duplicate entries")
+	if samePrecedence(sv1, sv2) {
+		if !prog.chkDups {
+			prog.reportSeqErr(e1, e2, probDup, dupMsg(sv1, sv2))
+		}
+
 		return
 	}
+
+	if prog.chkPreRelSeq {
+		prog.chkPreRelProgression(e1, e2)
+	}
 }
 
-// makeSV will try to create a semver from the passed string. If the string
-// cannot be converted then a nil pointer and an error will be returned and
-// the exitStatus will be set to 1. Otherwise a pointer to a well-formed
-// semver and a nil error will be returned.
-func (prog *prog) makeSV(s string) (sv *semver.SV, err error) {
-	defer func() {
-		if err != nil {
-			prog.exitStatus = 1
+// makeSV will try to create a semver from the entry's string. If the
+// string cannot be converted or the semver breaks the pre-release or build
+// ID rules then the entry's sv will be left as nil, the corresponding error
+// will be recorded in the entry and returned, and the problem will be
+// recorded. Otherwise the entry's sv will be set to the well-formed semver
+// and a nil error will be returned.
+func (prog *prog) makeSV(e *entry) error {
+	if prog.datedInput {
+		if err := e.splitDate(); err != nil {
+			return prog.parseFailed(e, err)
 		}
-	}()
+	}
 
-	sv, err = semver.ParseSV(s)
+	svStr, offset, err := prog.extractSV(e.svStr)
 	if err != nil {
-		return nil, err
+		return prog.parseFailed(e, err)
+	}
+
+	e.svStr, e.svOffset = svStr, offset
+	if prog.byModule {
+		e.module, e.svStr = splitModule(e.svStr)
+		e.svOffset += len(svStr) - len(e.svStr)
+	}
+
+	sv, err := semver.ParseSV(e.svStr)
+	if err != nil {
+		e.suggestion, _ = suggestFix(e.svStr)
+		return prog.parseFailed(e, err)
 	}
 
 	err = semver.CheckRules(sv.PreRelIDs(), prog.semverChecks.PreRelIDChecks)
 	if err != nil {
-		return nil, fmt.Errorf("bad pre-release IDs: %s", err)
+		return prog.idRuleFailed(e, fmt.Errorf("bad pre-release IDs: %s", err))
 	}
 
 	err = semver.CheckRules(sv.BuildIDs(), prog.semverChecks.BuildIDChecks)
 	if err != nil {
-		return nil, fmt.Errorf("bad build IDs: %s", err)
+		return prog.idRuleFailed(e, fmt.Errorf("bad build IDs: %s", err))
 	}
 
-	return sv, nil
+	e.sv = sv
+
+	return nil
+}
+
+// parseFailed records the parse error against the entry and records the
+// problem. It returns the error.
+func (prog *prog) parseFailed(e *entry, err error) error {
+	e.parseErr = err
+	prog.fail(probParse)
+
+	return err
+}
+
+// idRuleFailed records the ID rule error against the entry and records the
+// problem. It returns the error.
+func (prog *prog) idRuleFailed(e *entry, err error) error {
+	e.idErr = err
+	prog.fail(probIDRule)
+
+	return err
 }
 
 // getSVsFromStdin will read semver strings from standard input
-// and check them. It returns a list of all the valid semvers.
-func (prog *prog) getSVsFromStdin() []*semver.SV {
-	svl := []*semver.SV{}
+// and check them. It returns a list of entries, one per line read.
+func (prog *prog) getSVsFromStdin() []*entry {
+	el, _ := prog.getSVsFromReader(os.Stdin, "standard input")
+	return el
+}
+
+// getSVsFromReader will read semver strings from the reader and check
+// them. The locations of the entries are given by the name and the line
+// number. It returns a list of entries, one per line read (apart from any
+// skipped lines), and any error encountered while reading.
+func (prog *prog) getSVsFromReader(r io.Reader, name string,
+) ([]*entry, error) {
+	el := []*entry{}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	loc := location.New("standard input")
+	skipped, err := prog.readEntries(r, name,
+		func(e *entry) { el = append(el, e) })
+
+	if len(el) > 0 {
+		el[len(el)-1].skippedAfter = skipped
+	} else {
+		printSkipped(skipped)
+	}
+
+	return el, err
+}
+
+// readEntries will read semver strings from the reader and check
+// them. Each entry made is passed to the function as soon as it has been
+// read. The locations of the entries are given by the name and the line
+// number. In fix mode the lines skipped before each entry are recorded in
+// the entry so that they can be printed with it and any lines skipped
+// after the last entry are returned. Any error encountered while reading
+// is returned.
+func (prog *prog) readEntries(r io.Reader, name string, f func(*entry),
+) ([]location.L, error) {
+	scanner := bufio.NewScanner(r)
+	loc := location.New(name)
+	idx := 0
+
+	var skipped []location.L
 
 	for scanner.Scan() {
 		loc.Incr()
 		loc.SetContent(scanner.Text())
-		svl = append(svl, prog.mkRptPrt(loc))
+
+		if prog.skipLine(scanner.Text()) {
+			if prog.fix {
+				skipped = append(skipped, *loc)
+			}
+
+			continue
+		}
+
+		e := prog.mkRptPrt(loc, idx)
+		e.skipped = skipped
+		skipped = nil
+
+		f(e)
+		idx++
 	}
 
-	return svl
+	return skipped, scanner.Err()
 }
 
 // getSVsFromStrings will read semver strings from the passed list of
-// strings and check them. It returns a list of all the valid semvers
-func (prog *prog) getSVsFromStrings(args []string) []*semver.SV {
-	svl := make([]*semver.SV, 0, len(args))
+// strings and check them. It returns a list of entries, one per string.
+func (prog *prog) getSVsFromStrings(args []string) []*entry {
+	el := make([]*entry, 0, len(args))
 
 	loc := location.New("argument")
 	for _, s := range args {
 		loc.Incr()
 		loc.SetContent(s)
-		svl = append(svl, prog.mkRptPrt(loc))
+		el = append(el, prog.mkRptPrt(loc, len(el)))
 	}
 
-	return svl
+	return el
 }
 
-// mkRptPrt creates a semver from the passed string, reports any
-// errors and adds the created semver to the list of semvers. It will also,
-// optionally, print the semver.
-func (prog *prog) mkRptPrt(loc *location.L) *semver.SV {
-	s, hasContent := loc.Content()
-	if !hasContent {
-		panic(fmt.Errorf(
-			"program error: the location should have content: %s", loc))
-	}
-
-	sv, err := prog.makeSV(s)
-	if err != nil {
-		fmt.Println(loc)
-		fmt.Println("   ", err)
+// mkRptPrt creates an entry for the location and makes a semver from the
+// location content, reporting any errors. It will also, optionally, print
+// the semver. The entry is returned whether or not a semver could be made.
+func (prog *prog) mkRptPrt(loc *location.L, idx int) *entry {
+	e := newEntry(loc, idx)
 
-		return nil
+	if err := prog.makeSV(e); err != nil {
+		prog.reportSVErr(e, err)
+		return e
 	}
 
-	if prog.printSV {
-		fmt.Println(sv)
+	if prog.printSV && prog.format == fmtText && !prog.fix {
+		fmt.Println(e.sv)
 	}
 
-	return sv
+	return e
 }
 
 // addParams adds the program-specific parameters
@@ -254,6 +649,483 @@ func (prog *prog) addParams() param.PSetOptFunc {
 				" are correctly ordered and that there are"+
 				" no gaps in the sequence",
 			param.AltNames("check-order", "check-list"),
+			param.PostAction(prog.recordParamSet),
+		)
+
+		ps.Add("check-seq-by",
+			psetter.Enum[string]{
+				Value: &prog.seqBy,
+				AllowedVals: psetter.AllowedVals[string]{
+					seqByAll: "check all the " + semver.Names +
+						" as a single sequence",
+					seqByMajor: "check the " + semver.Names +
+						" having the same major version" +
+						" as a separate sequence",
+					seqByMinor: "check the " + semver.Names +
+						" having the same major and minor versions" +
+						" as a separate sequence",
+				},
+			},
+			"how the "+semver.Names+" should be grouped into release"+
+				" lines when checking the sequence. This allows several"+
+				" release lines to be maintained at the same time, for"+
+				" instance with patches to v1.4 being released after"+
+				" v2.0.0. The order and gaps of each release line are"+
+				" checked separately and any problems report the name"+
+				" of the release line."+
+				" This implies that the sequence is checked",
+			param.AltNames("seq-by"),
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+		)
+
+		ps.Add(paramNameChkPreRelSeq, psetter.Bool{Value: &prog.chkPreRelSeq},
+			"check the sequence of pre-release IDs. Within a pre-release"+
+				" stage (such as 'rc') the numeric part of the last"+
+				" pre-release ID must grow by one, so 'rc.1' must be"+
+				" followed by 'rc.2'. A sequence of pre-releases must"+
+				" end in a release before the next version is started."+
+				" If a ladder of pre-release stages has been given then"+
+				" the stages must be followed in order without any"+
+				" being skipped and each new version must start at"+
+				" the first stage."+
+				" This implies that the sequence is checked",
+			param.AltNames("check-prid-seq"),
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+			param.SeeAlso(paramNamePreRelLadder),
+		)
+
+		ps.Add(paramNamePreRelLadder,
+			psetter.StrList[string]{
+				Value: &prog.preRelLadder,
+				Checks: []check.ValCk[[]string]{
+					check.SliceHasNoDups[[]string],
+				},
+			},
+			"the ordered list of pre-release stages. The stage of a"+
+				" pre-release is the first pre-release ID up to any"+
+				" digits so the stage of both 'rc.1' and 'rc1' is 'rc'."+
+				" This implies that the sequence of pre-release IDs"+
+				" is checked",
+			param.AltNames("prid-ladder"),
+			param.PostAction(paction.SetVal(&prog.chkPreRelSeq, true)),
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+			param.SeeAlso(paramNameChkPreRelSeq),
+		)
+
+		ps.Add(paramNameChkDups, psetter.Bool{Value: &prog.chkDups},
+			"check that no two "+semver.Names+" have the same"+
+				" precedence. Build IDs do not affect the precedence"+
+				" and so, for instance, v1.2.3+a and v1.2.3+b are"+
+				" duplicates. The whole list is checked, whether or"+
+				" not it is in order, and each group of duplicates is"+
+				" reported together with their locations",
+			param.AltNames("check-duplicates"),
+			param.SeeAlso(paramNameStream),
+		)
+
+		ps.Add(paramNameFillGaps, psetter.Bool{Value: &prog.fillGaps},
+			"print the complete expected sequence of "+semver.Names+
+				". Each valid "+semver.Name+" is printed and any"+
+				" which are missing from the sequence are printed in"+
+				" their expected place followed by '# missing'."+
+				" This implies that the sequence is checked."+
+				" Nothing is printed if the format is not '"+fmtText+"'",
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+		)
+
+		ps.Add("max-jump",
+			psetter.Int[int]{
+				Value:  &prog.maxJump,
+				Checks: []check.ValCk[int]{check.ValGT(0)},
+			},
+			"the largest amount by which any part of the "+semver.Name+
+				" may grow from one value to the next without it being"+
+				" reported as a gap. This can be overridden for"+
+				" individual parts."+
+				" This implies that the sequence is checked",
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+			param.PostAction(prog.recordParamSet),
+			param.SeeAlso(paramNameAllowMissing),
+		)
+
+		for _, pj := range []struct {
+			name string
+			val  *int
+		}{
+			{name: "major", val: &prog.maxMajorJump},
+			{name: "minor", val: &prog.maxMinorJump},
+			{name: "patch", val: &prog.maxPatchJump},
+		} {
+			ps.Add("max-"+pj.name+"-jump",
+				psetter.Int[int]{
+					Value:  pj.val,
+					Checks: []check.ValCk[int]{check.ValGT(0)},
+				},
+				"the largest amount by which the "+pj.name+" version"+
+					" may grow from one value to the next without it"+
+					" being reported as a gap."+
+					" This implies that the sequence is checked",
+				param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+				param.PostAction(prog.recordParamSet),
+				param.SeeAlso("max-jump"),
+			)
+		}
+
+		ps.Add(paramNameAllowMissing,
+			psetter.StrList[string]{Value: &prog.allowMissing},
+			"the "+semver.Names+" which are known to be missing from"+
+				" the sequence, for instance because they were"+
+				" withdrawn. A gap in the sequence is not reported if"+
+				" all the "+semver.Names+" missing from it are in"+
+				" this list. Any pre-release or build IDs are ignored."+
+				" This implies that the sequence is checked",
+			param.AltNames("allow-gaps", "known-gaps"),
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+		)
+
+		ps.AddFinalCheck(func() error {
+			for _, s := range prog.allowMissing {
+				if err := prog.addAllowedMissing(s); err != nil {
+					return fmt.Errorf("bad value for the %q parameter: %w",
+						paramNameAllowMissing, err)
+				}
+			}
+
+			return nil
+		})
+
+		ps.Add(paramNameGitRepo,
+			psetter.Pathname{
+				Value:       &prog.gitRepo,
+				Expectation: filecheck.DirExists(),
+			},
+			"read the "+semver.Names+" from the tags of the local git"+
+				" repository in this directory rather than from the"+
+				" standard input. Both loose and packed tags are read"+
+				" and they are sorted into "+semver.Name+" order before"+
+				" being checked. Any problems are reported with the"+
+				" name of the tag reference",
+			param.AltNames("git"),
+		)
+
+		ps.Add(paramNameByModule, psetter.Bool{Value: &prog.byModule},
+			"each value is a module path prefix followed by a '/'"+
+				" and a "+semver.Name+", as used in the tags of a"+
+				" repository holding several modules (for instance,"+
+				" 'api/v1.2.3' or 'tools/gen/v2.0.1'). A value with no"+
+				" prefix is a version of the module at the root of the"+
+				" repository. The "+semver.Names+" of each module are"+
+				" checked as a separate list and a summary of the"+
+				" checks is given for each module",
+			param.AltNames("monorepo"),
+			param.SeeAlso(paramNameModuleRoot),
+		)
+
+		ps.Add(paramNameModuleRoot,
+			psetter.Pathname{
+				Value:       &prog.moduleRoot,
+				Expectation: filecheck.DirExists(),
+			},
+			"the directory at the root of the local tree holding the"+
+				" modules. Each module path prefix must name a"+
+				" directory in this tree having a go.mod file, as must"+
+				" the root of the tree if there are versions of the"+
+				" root module, and the "+semver.Names+" of the module"+
+				" are checked against the module path and retractions"+
+				" in that file. If this is not given but the "+
+				paramNameGitRepo+" parameter is then that directory is"+
+				" used, otherwise the current directory is used. This"+
+				" implies that the values are checked by module",
+			param.PostAction(paction.SetVal(&prog.byModule, true)),
+			param.SeeAlso(paramNameByModule),
+		)
+
+		ps.Add(paramNameFiles, psetter.StrList[string]{Value: &prog.files},
+			"read the "+semver.Names+" from these files rather than from"+
+				" the standard input. Any file names containing glob"+
+				" patterns are expanded. The file names must be"+
+				" separated by commas, not spaces, and so any glob"+
+				" pattern should be quoted so that it is expanded by"+
+				" this program rather than by the shell (for instance,"+
+				" -files 'a.txt,releases/*.txt'). Each file is checked"+
+				" as a separate list and any problems are reported"+
+				" with the file name and line number",
+			param.AltNames("file", "f"),
+			param.SeeAlso(paramNameGitRepo),
+		)
+
+		ps.Add(paramNameStream, psetter.Bool{Value: &prog.stream},
+			"check and report on each "+semver.Name+" as soon as it"+
+				" is read rather than reading the whole list first."+
+				" Each value is checked against its predecessor (in"+
+				" the same release line) and only those needed for the"+
+				" checks are kept so very long lists can be checked"+
+				" using little memory. The problems found are the same"+
+				" as when the whole list is read first but they may be"+
+				" reported in a different order. A warning that the"+
+				" highest version has been retracted can only be given"+
+				" after the whole list has been read; with the '"+
+				fmtJSON+"' format this is written to the standard error."+
+				" This cannot be used with the "+paramNameGitRepo+
+				" parameter as the tags are sorted before being checked",
+			param.AltNames("streaming"),
+			param.SeeAlso(paramNameGitRepo),
+		)
+
+		ps.AddFinalCheck(func() error {
+			if prog.stream && prog.gitRepo != "" {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameStream, paramNameGitRepo)
+			}
+
+			if prog.stream && prog.chkDups {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameStream, paramNameChkDups)
+			}
+
+			if prog.stream && prog.apiOld != "" {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameStream, paramNameAPIOld)
+			}
+
+			return nil
+		})
+
+		ps.Add(paramNameFix, psetter.Bool{Value: &prog.fix},
+			"print the values read with any malformed "+semver.Names+
+				" replaced by a corrected form. Any other text in"+
+				" the value is left unchanged, as are any lines which"+
+				" are skipped as comments or blank lines. Values which"+
+				" cannot be corrected are printed as they are. The"+
+				" report of any problems found is written to the"+
+				" standard error rather than the standard output",
+			param.SeeAlso(paramNameFillGaps),
+		)
+
+		ps.AddFinalCheck(func() error {
+			if prog.fix && prog.fillGaps {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameFix, paramNameFillGaps)
+			}
+
+			return nil
+		})
+
+		ps.Add("comment", psetter.StrList[string]{Value: &prog.commentMarkers},
+			"any lines read which start with one of these markers"+
+				" (after any leading white space) are ignored",
+			param.AltNames("comment-markers"),
+		)
+
+		ps.Add("ignore-blank-lines", psetter.Bool{Value: &prog.ignoreBlankLines},
+			"any lines read which are empty or only contain white space"+
+				" are ignored",
+			param.AltNames("skip-blank-lines"),
+		)
+
+		ps.Add("column",
+			psetter.Int[int]{
+				Value:  &prog.column,
+				Checks: []check.ValCk[int]{check.ValGT(0)},
+			},
+			"the "+semver.Name+" is taken from this column of each value."+
+				" The value is split on white space and columns are"+
+				" numbered from 1. If this is not given the whole value is"+
+				" used",
+			param.AltNames("col"),
+		)
+
+		ps.Add("prefix", psetter.Regexp{Value: &prog.prefix},
+			"the pattern to be used to strip the "+semver.Name+" of any"+
+				" prefix. Any text at the start of the value matching"+
+				" this regular expression will be removed before"+
+				" the value is converted into a "+semver.Name,
+		)
+
+		ps.Add(paramNameDatedInput, psetter.Bool{Value: &prog.datedInput},
+			"each value read is a "+semver.Name+" followed by a tab"+
+				" and the date on which it was released. The date"+
+				" should be in the form "+dateFmtDesc,
+			param.SeeAlso(paramNameChkChrono),
+		)
+
+		ps.Add(paramNameChkChrono, psetter.Bool{Value: &prog.chkChrono},
+			"check that no "+semver.Name+" was released before a"+
+				" lesser one. The release dates are taken from the"+
+				" tags when reading from a git repository (the tag"+
+				" time for an annotated tag, otherwise the commit"+
+				" time) or from dated input. Each "+semver.Name+" is"+
+				" only compared with the one before it having the same"+
+				" major and minor versions so a patch to an older"+
+				" release line may be released after a newer release."+
+				" This implies that the sequence is checked",
+			param.AltNames("check-dates"),
+			param.PostAction(paction.SetVal(&prog.checkSeq, true)),
+			param.SeeAlso(paramNameDatedInput, paramNameGitRepo),
+		)
+
+		ps.AddFinalCheck(func() error {
+			if prog.chkChrono && prog.gitRepo == "" && !prog.datedInput {
+				return fmt.Errorf("the %q parameter needs release dates;"+
+					" give either the %q or the %q parameter",
+					paramNameChkChrono, paramNameGitRepo, paramNameDatedInput)
+			}
+
+			if prog.gitRepo != "" && len(prog.files) > 0 {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameGitRepo, paramNameFiles)
+			}
+
+			if prog.datedInput && prog.gitRepo != "" {
+				return fmt.Errorf("the %q and %q parameters"+
+					" cannot both be given",
+					paramNameDatedInput, paramNameGitRepo)
+			}
+
+			return nil
+		})
+
+		ps.Add(paramNameGoMod,
+			psetter.Pathname{
+				Value:       &prog.goModFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"check each "+semver.Name+" against the module path in this"+
+				" go.mod file. A Go module with a major version of 2 or"+
+				" more must have a module path ending in the major"+
+				" version (for instance '/v2') and one with a major"+
+				" version of 0 or 1 must not. Any "+semver.Name+
+				" which cannot be a version of the module is reported."+
+				" Any retract directives in the go.mod file are also"+
+				" read. When checking the sequence a retracted version"+
+				" may be missing without it being reported as a gap"+
+				" and a warning is given if the highest version has"+
+				" been retracted. When checking by module this file is"+
+				" only used for the module at the root of the tree, in"+
+				" place of any go.mod file found there",
+			param.AltNames("gomod"),
+		)
+
+		ps.Add(paramNameChangelog,
+			psetter.Pathname{
+				Value:       &prog.changelogFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"check the "+semver.Names+" against the version headings"+
+				" in this changelog. The changelog is expected to be"+
+				" in the 'keep a changelog' style with a level 2"+
+				" heading for each release, newest first, such as"+
+				" '## [1.2.3] - 2024-05-01'; the 'Unreleased' heading"+
+				" is ignored. Any "+semver.Name+" with no heading is"+
+				" reported, as is any heading with no matching "+
+				semver.Name+" and any heading out of order. Build"+
+				" IDs are ignored when matching and a pre-release"+
+				" need not have a heading",
+			param.AltNames("change-log", "CHANGELOG"),
+		)
+
+		ps.Add(paramNameAPIOld, psetter.String[string]{Value: &prog.apiOld},
+			"check that the change between the last two "+semver.Names+
+				" in the list is large enough for the changes to the"+
+				" exported Go API between this source tree and the"+
+				" one given by the "+paramNameAPINew+" parameter."+
+				" The source tree may be a directory or a git revision"+
+				" (a tag, branch or full commit ID) of the repository"+
+				" given by the "+paramNameGitRepo+" parameter or, if"+
+				" that is not given, of the repository in the current"+
+				" directory. An incompatible change to the API needs a"+
+				" new major version (or a new minor version if the"+
+				" major version is 0) and an addition to the API needs"+
+				" at least a new minor version. Test files, packages"+
+				" named main and the internal, testdata and vendor"+
+				" directories are not part of the API",
+			param.AltNames("api-from"),
+			param.SeeAlso(paramNameAPINew),
+		)
+
+		ps.Add(paramNameAPINew, psetter.String[string]{Value: &prog.apiNew},
+			"the source tree holding the newer version of the Go API"+
+				" to be compared with the one given by the "+
+				paramNameAPIOld+" parameter. This may be a directory"+
+				" or a git revision. If it is not given the work tree"+
+				" of the repository given by the "+paramNameGitRepo+
+				" parameter is used or, if that is not given, the"+
+				" current directory",
+			param.AltNames("api-to"),
+			param.SeeAlso(paramNameAPIOld),
+		)
+
+		ps.Add(paramNamePolicy,
+			psetter.Pathname{
+				Value:       &prog.policyFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"check the "+semver.Names+" against the rules in this"+
+				" policy file. Each line of the file gives a rule name"+
+				" optionally followed by '=' and a value. Blank lines"+
+				" are ignored and comments start with '#'. Other policy"+
+				" files can be included with '"+
+				fileparse.DefaultInclKeyWord+" <file>'. A rule given"+
+				" without a value is set to true. The rules are:\n- "+
+				strings.Join(policyRuleDescs, "\n- ")+
+				"\n\nA rule which is also given as a parameter, such as"+
+				" '"+polMaxJump+"', is ignored; the parameter takes"+
+				" precedence."+
+				"\n\nThis parameter can be given in a configuration"+
+				" file so that the same policy is applied on every run",
+		)
+
+		ps.Add("format",
+			psetter.Enum[string]{
+				Value: &prog.format,
+				AllowedVals: psetter.AllowedVals[string]{
+					fmtText: "report problems as free-form text" +
+						" as they are found",
+					fmtJSON: "report every value read as a JSON object," +
+						" one per line, once all the checks are complete." +
+						" Each record gives the source and line, the index" +
+						" in the list, the value read and any parse error," +
+						" pre-release or build ID rule failure and" +
+						" sequence errors (with the indices of both" +
+						" values compared)",
+					fmtSARIF: "report the problems found as a SARIF " +
+						sarifVersion + " log once all the checks are" +
+						" complete. Each problem is a result whose rule" +
+						" ID is the category of the problem. Values read" +
+						" from files are given the file name and line as" +
+						" a physical location, other values are given a" +
+						" logical location",
+				},
+			},
+			"how the results of the checks should be reported",
+		)
+
+		ps.Add("exit-status",
+			psetter.Enum[string]{
+				Value: &prog.exitMode,
+				AllowedVals: psetter.AllowedVals[string]{
+					exitModeSimple: "exit with status 1 if any" +
+						" problem is found",
+					exitModeCategory: "exit with a status giving the" +
+						" category of problem found: " +
+						exitCodesDesc(exitModeCategory) +
+						". If problems of more than one category are" +
+						" found the first of these is used",
+					exitModeBitmask: "exit with a status in which a bit" +
+						" is set for each category of problem found: " +
+						exitCodesDesc(exitModeBitmask),
+				},
+			},
+			"how the exit status should be set if problems are found."+
+				" The exit status is 0 if no problems are found and"+
+				" 1 if the program cannot run, for instance if the"+
+				" input cannot be read",
+			param.AltNames("exit-mode"),
 		)
 
 		return nil
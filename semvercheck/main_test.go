@@ -87,7 +87,7 @@ func TestMakeSlice(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		prog := &prog{}
+		prog := newProg()
 		fName := filepath.Join(testDataDir, "semvers", tc.Name+".txt")
 
 		f, err := os.Open(fName) //nolint:gosec
@@ -139,3 +139,48 @@ func TestMakeSlice(t *testing.T) {
 			prog.exitStatus, tc.expExitStatus)
 	}
 }
+
+func TestJSONReport(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		expExitStatus int
+	}{
+		{
+			ID:    testhelper.MkID("good"),
+			input: "v1.0.0\nv1.0.1\nv1.1.0\n",
+		},
+		{
+			ID:            testhelper.MkID("bad"),
+			input:         "v1.2.0\nv1.1.0\nbad\nv1.1.0\nv1.4.0-x\n",
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.format = fmtJSON
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		el := prog.getSVsFromStdin()
+		prog.seqCheck(el)
+		prog.report(el)
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "report."+tc.Name+".json", stdout)
+
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// rootModuleName is the name used when reporting on the module at the root
+// of the tree, whose tags have no module prefix
+const rootModuleName = "(root)"
+
+// splitModule splits the value into the module path prefix and the semver
+// string. The prefix is everything up to the last '/'; a value without a
+// '/' has an empty prefix, that is, it is a version of the root module.
+func splitModule(s string) (string, string) {
+	i := strings.LastIndex(s, "/")
+	if i < 0 {
+		return "", s
+	}
+
+	return s[:i], s[i+1:]
+}
+
+// moduleName returns the name of the module for reporting
+func moduleName(module string) string {
+	if module == "" {
+		return rootModuleName
+	}
+
+	return module
+}
+
+// groupByModule splits the entries into groups, one per module, preserving
+// the order in which the entries appear. The groups are returned in the
+// order in which each module was first seen.
+func groupByModule(el []*entry) [][]*entry {
+	groups := [][]*entry{}
+	groupIdx := map[string]int{}
+
+	for _, e := range el {
+		idx, ok := groupIdx[e.module]
+		if !ok {
+			idx = len(groups)
+			groupIdx[e.module] = idx
+			groups = append(groups, []*entry{})
+		}
+
+		groups[idx] = append(groups[idx], e)
+	}
+
+	return groups
+}
+
+// moduleTreeRoot returns the directory at the root of the local tree
+// holding the modules. This is the module root if it has been given,
+// otherwise the git repository if that has been given, otherwise the
+// current directory.
+func (prog *prog) moduleTreeRoot() string {
+	switch {
+	case prog.moduleRoot != "":
+		return prog.moduleRoot
+	case prog.gitRepo != "":
+		return prog.gitRepo
+	}
+
+	return "."
+}
+
+// rootGoModGiven returns true if the module is the root module and a
+// go.mod file has been given by parameter for it
+func (prog *prog) rootGoModGiven(module string) bool {
+	return module == "" && prog.goMod.modPath != ""
+}
+
+// moduleDirErr returns an error if the module is not in the local tree,
+// nil otherwise. A module is in the tree if the directory given by its path
+// prefix has a go.mod file; the root module is at the root of the tree. The
+// go.mod file is read and recorded so that the semvers of the module can be
+// checked against it. The root module is not looked for if a go.mod file
+// has been given by parameter.
+func (prog *prog) moduleDirErr(module string) error {
+	if prog.rootGoModGiven(module) {
+		return nil
+	}
+
+	root := prog.moduleTreeRoot()
+	goModPath := filepath.Join(root, filepath.FromSlash(module), "go.mod")
+
+	_, err := os.Stat(goModPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("there is no module %q in %q (no file: %q)",
+			moduleName(module), root, goModPath)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := prog.moduleGoMods[module]; ok {
+		return nil
+	}
+
+	gm, err := readGoMod(goModPath)
+	if err != nil {
+		return err
+	}
+
+	prog.moduleGoMods[module] = gm
+
+	return nil
+}
+
+// goModOf returns the go.mod details against which the semvers of the
+// module are checked. When checking by module, a module is checked against
+// its own go.mod file, if it has been found in the local tree, unless it
+// is the root module and a go.mod file has been given by parameter.
+// Otherwise the go.mod file given by parameter is used.
+func (prog *prog) goModOf(module string) goMod {
+	if prog.byModule && !prog.rootGoModGiven(module) {
+		return prog.moduleGoMods[module]
+	}
+
+	return prog.goMod
+}
+
+// chkModuleDirs checks that the module of each entry is in the local
+// tree. The problem is reported once for each module but recorded against
+// every entry.
+func (prog *prog) chkModuleDirs(el []*entry) {
+	if prog.moduleDirReported == nil {
+		prog.moduleDirReported = map[string]bool{}
+	}
+
+	reported := prog.moduleDirReported
+
+	for _, e := range el {
+		err := prog.moduleDirErr(e.module)
+		if err == nil {
+			continue
+		}
+
+		e.modErr = err
+		prog.fail(probOther)
+
+		if !reported[e.module] {
+			reported[e.module] = true
+			prog.reportSVErr(e, err)
+		}
+	}
+}
+
+// moduleStats records the summary of the checks of a single module
+type moduleStats struct {
+	name     string
+	count    int
+	highest  *semver.SV
+	problems int
+}
+
+// problemCount returns the number of problems found with the entry
+func (e entry) problemCount() int {
+	count := len(e.policyErrs) + len(e.changelogErrs) + len(e.seqErrs)
+
+	for _, err := range []error{e.parseErr, e.idErr, e.modErr, e.apiErr} {
+		if err != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+// addToSummary adds the entry to the summary for its module
+func (prog *prog) addToSummary(e *entry) {
+	if prog.moduleSummary == nil {
+		prog.moduleSummary = map[string]*moduleStats{}
+	}
+
+	ms, ok := prog.moduleSummary[e.module]
+	if !ok {
+		ms = &moduleStats{name: moduleName(e.module)}
+		prog.moduleSummary[e.module] = ms
+		prog.moduleOrder = append(prog.moduleOrder, e.module)
+	}
+
+	ms.count++
+	ms.problems += e.problemCount()
+
+	if e.sv != nil && (ms.highest == nil || semver.Less(ms.highest, e.sv)) {
+		ms.highest = e.sv
+	}
+}
+
+// printModuleSummary prints the summary of the checks for each module in
+// the order in which the modules were first seen
+func (prog *prog) printModuleSummary() {
+	if prog.format != fmtText || len(prog.moduleOrder) == 0 {
+		return
+	}
+
+	w := prog.rptOut()
+
+	width := 0
+	for _, m := range prog.moduleOrder {
+		width = max(width, len(prog.moduleSummary[m].name))
+	}
+
+	fmt.Fprintln(w, "Module summary:")
+
+	for _, m := range prog.moduleOrder {
+		ms := prog.moduleSummary[m]
+
+		highest := "none"
+		if ms.highest != nil {
+			highest = ms.highest.String()
+		}
+
+		fmt.Fprintf(w, "    %-*s %d %s, highest: %s, problems: %d\n",
+			width, ms.name, ms.count, semver.Names, highest, ms.problems)
+	}
+}
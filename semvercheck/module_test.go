@@ -0,0 +1,198 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSplitModule(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		s         string
+		expModule string
+		expSVStr  string
+	}{
+		{
+			ID:       testhelper.MkID("root"),
+			s:        "v1.2.3",
+			expSVStr: "v1.2.3",
+		},
+		{
+			ID:        testhelper.MkID("one level"),
+			s:         "api/v1.2.3",
+			expModule: "api",
+			expSVStr:  "v1.2.3",
+		},
+		{
+			ID:        testhelper.MkID("two levels"),
+			s:         "tools/gen/v2.0.1",
+			expModule: "tools/gen",
+			expSVStr:  "v2.0.1",
+		},
+		{
+			ID:        testhelper.MkID("build IDs"),
+			s:         "api/v1.2.3+a.b",
+			expModule: "api",
+			expSVStr:  "v1.2.3+a.b",
+		},
+	}
+
+	for _, tc := range testCases {
+		module, svStr := splitModule(tc.s)
+		testhelper.DiffString(t, tc.IDStr(), "module", module, tc.expModule)
+		testhelper.DiffString(t, tc.IDStr(), "semver", svStr, tc.expSVStr)
+	}
+}
+
+func TestByModule(t *testing.T) {
+	const input = "v1.0.0\n" +
+		"api/v1.0.0\n" +
+		"tools/gen/v0.1.0\n" +
+		"v1.0.1\n" +
+		"api/v1.2.0\n" +
+		"tools/gen/v0.2.0\n" +
+		"api/v1.2.1\n" +
+		"web/v1.0.0\n" +
+		"web/v1.0.1\n"
+
+	testCases := []struct {
+		testhelper.ID
+		moduleRoot    string
+		stream        bool
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("seq"),
+			moduleRoot:    filepath.Join("testdata", "monorepo"),
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("seq-stream"),
+			moduleRoot:    filepath.Join("testdata", "monorepo"),
+			stream:        true,
+			expExitStatus: 1,
+		},
+		{
+			// the modules are looked for in the current directory
+			ID:            testhelper.MkID("no-root"),
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.checkSeq = true
+		prog.byModule = true
+		prog.moduleRoot = tc.moduleRoot
+
+		stdout, exitStatus := runCheck(t, prog, input, tc.stream)
+
+		name := tc.Name
+		if tc.stream {
+			name = "seq"
+		}
+
+		gfc.Check(t, tc.IDStr(), "module."+name, stdout)
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			exitStatus, tc.expExitStatus)
+	}
+}
+
+func TestByModuleGoMod(t *testing.T) {
+	const input = "v2.0.0\n" +
+		"api/v1.0.0\n" +
+		"api/v2.0.0\n" +
+		"lib/v1.0.0\n" +
+		"lib/v2.0.0\n" +
+		"lib/v2.0.1\n"
+
+	testCases := []struct {
+		testhelper.ID
+		rootModPath string
+		stream      bool
+		goldenName  string
+	}{
+		{
+			ID:          testhelper.MkID("list"),
+			rootModPath: "example.com/mono/v2",
+			goldenName:  "gomod",
+		},
+		{
+			ID:          testhelper.MkID("stream"),
+			rootModPath: "example.com/mono/v2",
+			stream:      true,
+			goldenName:  "gomod",
+		},
+		{
+			ID:         testhelper.MkID("root go.mod"),
+			goldenName: "gomod-root",
+		},
+		{
+			ID:         testhelper.MkID("root go.mod stream"),
+			stream:     true,
+			goldenName: "gomod-root",
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.byModule = true
+		prog.moduleRoot = filepath.Join("testdata", "monorepo")
+		prog.goMod = goMod{modPath: tc.rootModPath}
+
+		stdout, exitStatus := runCheck(t, prog, input, tc.stream)
+
+		gfc.Check(t, tc.IDStr(), "module."+tc.goldenName, stdout)
+		testhelper.DiffInt(t, tc.IDStr(), "exit status", exitStatus, 1)
+	}
+}
+
+func TestModuleDirErr(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		moduleRoot string
+		gitRepo    string
+		module     string
+	}{
+		{
+			ID:         testhelper.MkID("module root"),
+			moduleRoot: filepath.Join("testdata", "monorepo"),
+			module:     "api",
+		},
+		{
+			ID:         testhelper.MkID("root module"),
+			moduleRoot: filepath.Join("testdata", "monorepo"),
+		},
+		{
+			ID:      testhelper.MkID("git repo"),
+			gitRepo: filepath.Join("testdata", "monorepo"),
+			module:  "web",
+			ExpErr: testhelper.MkExpErr(
+				`there is no module "web" in "testdata/monorepo"`),
+		},
+		{
+			ID:     testhelper.MkID("no root"),
+			module: "web",
+			ExpErr: testhelper.MkExpErr(`there is no module "web" in "."`),
+		},
+		{
+			ID: testhelper.MkID("no root, root module"),
+			ExpErr: testhelper.MkExpErr(
+				`there is no module "(root)" in "."`),
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.byModule = true
+		prog.moduleRoot = tc.moduleRoot
+		prog.gitRepo = tc.gitRepo
+
+		err := prog.moduleDirErr(tc.module)
+		testhelper.CheckExpErr(t, err, tc)
+	}
+}
@@ -23,8 +23,9 @@ func makeParamSet(prog *prog) *param.PSet {
 		param.SetTrailingParamsName(semver.ShortName),
 		param.SetProgramDescription(
 			"Check the supplied semver strings."+
-				" This will read "+semver.Names+" from the standard input or"+
-				" passed as trailing arguments."+
+				" This will read "+semver.Names+" from the standard input,"+
+				" passed as trailing arguments, from named files or"+
+				" from the tags of a local git repository."+
 				" For each it will check that it is valid and also"+
 				" that it conforms to any additional constraints given."+
 				" If all the "+semver.Names+" are valid"+
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nickwells/fileparse.mod/fileparse"
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/param.mod/v7/param"
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// These are the names of the rules which can be given in a policy file
+const (
+	polAllowedPreRelID = "allowed-pre-rel-id"
+	polRequireBuildIDs = "require-build-ids"
+	polMaxPreRels      = "max-pre-releases"
+	polForbid          = "forbid"
+	polAllowV0         = "allow-v0"
+	polCheckSeq        = "check-seq"
+	polMaxJump         = "max-jump"
+	polMaxMajorJump    = "max-major-jump"
+	polMaxMinorJump    = "max-minor-jump"
+	polMaxPatchJump    = "max-patch-jump"
+	polAllowMissing    = "allow-missing"
+)
+
+// policyRuleDescs describes the rules which can be given in a policy file
+var policyRuleDescs = []string{
+	polAllowedPreRelID + " = <regexp>: every pre-release ID must match one" +
+		" of the patterns given; this may be given more than once",
+	polRequireBuildIDs + ": every " + semver.Name + " must have build IDs",
+	polMaxPreRels + " = <n>: no version may have more than this" +
+		" number of pre-releases",
+	polForbid + " = <" + semver.Name + ">: this version must not be" +
+		" present, build IDs are ignored; this may be given more than once",
+	polAllowV0 + " = false: no " + semver.Name + " may have a major" +
+		" version of 0",
+	polCheckSeq + ": the sequence of " + semver.Names + " is checked",
+	polMaxJump + " = <n>: when checking the sequence a part may grow by" +
+		" up to this amount without it being reported as a gap",
+	polMaxMajorJump + ", " + polMaxMinorJump + ", " + polMaxPatchJump +
+		" = <n>: as for " + polMaxJump + " but for the one part",
+	polAllowMissing + " = <" + semver.Name + ">: when checking the" +
+		" sequence this version may be missing; this may be given" +
+		" more than once",
+}
+
+// recordParamSet records that the parameter has been set. This is used for
+// the parameters having the same name as a policy rule so that a value
+// given as a parameter takes precedence over the rule in the policy file,
+// whichever is read first. It satisfies the param.ActionFunc type.
+func (prog *prog) recordParamSet(
+	_ location.L, p *param.BaseParam, _ []string,
+) error {
+	prog.paramsSet[p.Name()] = true
+
+	return nil
+}
+
+// setPolicyInt sets the value from the rule unless the parameter of the
+// same name has been set
+func (prog *prog) setPolicyInt(name, val string, v *int) error {
+	n, err := policyInt(val)
+	if err != nil {
+		return err
+	}
+
+	if !prog.paramsSet[name] {
+		*v = n
+	}
+
+	return nil
+}
+
+// policy records the rules from a policy file which apply to individual
+// semvers. The rules for checking the sequence are set directly on the
+// prog.
+type policy struct {
+	preRelIDPats    []*regexp.Regexp
+	requireBuildIDs bool
+	maxPreRels      int
+	forbidden       []*semver.SV
+	forbidV0        bool
+}
+
+// policyLineParser parses the lines of a policy file, satisfying the
+// fileparse.LineParser interface
+type policyLineParser struct {
+	prog *prog
+}
+
+// ParseLine parses a single line from the policy file. Each line is a rule
+// name optionally followed by an equals sign and a value. A rule with no
+// value is taken to be a boolean rule being set to true.
+func (plp policyLineParser) ParseLine(line string, loc *location.L) error {
+	loc.SetContent(line)
+
+	name, val, hasVal := strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	val = strings.TrimSpace(val)
+
+	if err := plp.prog.setPolicyRule(name, val, hasVal); err != nil {
+		return loc.Error(err.Error())
+	}
+
+	return nil
+}
+
+// policyBool returns the boolean value of the rule. If no value was given
+// the value is true.
+func policyBool(val string, hasVal bool) (bool, error) {
+	if !hasVal {
+		return true, nil
+	}
+
+	return strconv.ParseBool(val)
+}
+
+// policyInt returns the value of the rule as a number which must be
+// greater than zero
+func policyInt(val string) (int, error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("bad number: %q", val)
+	}
+
+	if n <= 0 {
+		return 0, fmt.Errorf("the value (%d) must be greater than zero", n)
+	}
+
+	return n, nil
+}
+
+// setPolicyRule sets the named rule from the value. A rule having the same
+// name as a parameter which has been set is checked but its value is
+// ignored.
+func (prog *prog) setPolicyRule(name, val string, hasVal bool) error {
+	pol := &prog.policy
+
+	var err error
+
+	switch name {
+	case polAllowedPreRelID:
+		var re *regexp.Regexp
+
+		if re, err = regexp.Compile(val); err == nil {
+			pol.preRelIDPats = append(pol.preRelIDPats, re)
+		}
+	case polRequireBuildIDs:
+		pol.requireBuildIDs, err = policyBool(val, hasVal)
+	case polMaxPreRels:
+		pol.maxPreRels, err = policyInt(val)
+	case polForbid:
+		var sv *semver.SV
+
+		if sv, err = semver.ParseSV(val); err == nil {
+			pol.forbidden = append(pol.forbidden, sv)
+		}
+	case polAllowV0:
+		var allowV0 bool
+
+		allowV0, err = policyBool(val, hasVal)
+		pol.forbidV0 = !allowV0
+	case polCheckSeq:
+		var checkSeq bool
+
+		checkSeq, err = policyBool(val, hasVal)
+		if err == nil && checkSeq && !prog.paramsSet[name] {
+			prog.checkSeq = true
+		}
+	case polMaxJump:
+		err = prog.setPolicyInt(name, val, &prog.maxJump)
+	case polMaxMajorJump:
+		err = prog.setPolicyInt(name, val, &prog.maxMajorJump)
+	case polMaxMinorJump:
+		err = prog.setPolicyInt(name, val, &prog.maxMinorJump)
+	case polMaxPatchJump:
+		err = prog.setPolicyInt(name, val, &prog.maxPatchJump)
+	case polAllowMissing:
+		err = prog.addAllowedMissing(val)
+	default:
+		return fmt.Errorf("unknown policy rule: %q", name)
+	}
+
+	if err != nil {
+		return fmt.Errorf("bad value for the %q rule: %w", name, err)
+	}
+
+	return nil
+}
+
+// readPolicy reads the policy file and records the rules
+func (prog *prog) readPolicy(fName string) error {
+	fp := fileparse.New("policy file", policyLineParser{prog: prog})
+
+	return errors.Join(fp.Parse(fName)...)
+}
+
+// policyErrs checks the semver against the policy and returns any
+// breaches of the rules
+func (pol policy) policyErrs(sv *semver.SV) []error {
+	errs := []error{}
+
+	if len(pol.preRelIDPats) > 0 {
+	prIDLoop:
+		for _, id := range sv.PreRelIDs() {
+			for _, re := range pol.preRelIDPats {
+				if re.MatchString(id) {
+					continue prIDLoop
+				}
+			}
+
+			errs = append(errs,
+				fmt.Errorf("the pre-release ID %q is not allowed by the policy",
+					id))
+		}
+	}
+
+	if pol.requireBuildIDs && !sv.HasBuildIDs() {
+		errs = append(errs,
+			errors.New("the policy requires build IDs but there are none"))
+	}
+
+	if pol.forbidV0 && sv.Major() == 0 {
+		errs = append(errs,
+			errors.New("the policy does not allow a major version of 0"))
+	}
+
+	for _, f := range pol.forbidden {
+		if samePrecedence(sv, f) {
+			errs = append(errs,
+				fmt.Errorf("the policy forbids %s", f))
+		}
+	}
+
+	return errs
+}
+
+// policyFailed records the policy errors against the entry, reports them
+// and records the problem
+func (prog *prog) policyFailed(e *entry, errs ...error) {
+	for _, err := range errs {
+		e.policyErrs = append(e.policyErrs, err)
+		prog.fail(probPolicy)
+		prog.reportSVErr(e, err)
+	}
+}
+
+// chkPolicy checks each entry in the list against the policy and reports
+// any breaches of the rules
+func (prog *prog) chkPolicy(el []*entry) {
+	preRelCount := map[string]int{}
+
+	for _, e := range el {
+		prog.chkPolicyEntry(e, preRelCount)
+	}
+}
+
+// chkPolicyEntry checks the entry against the policy and reports any
+// breaches of the rules. The count of pre-releases of each version seen so
+// far is updated.
+func (prog *prog) chkPolicyEntry(e *entry, preRelCount map[string]int) {
+	if e.sv == nil {
+		return
+	}
+
+	prog.policyFailed(e, prog.policy.policyErrs(e.sv)...)
+
+	if prog.policy.maxPreRels == 0 || !e.sv.HasPreRelIDs() {
+		return
+	}
+
+	vsn := vsnStr(e.sv)
+	key := e.module + " " + vsn
+
+	preRelCount[key]++
+	if preRelCount[key] == prog.policy.maxPreRels+1 {
+		prog.policyFailed(e,
+			fmt.Errorf("the policy allows at most %d pre-releases"+
+				" of %s", prog.policy.maxPreRels, vsn))
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestReadPolicy(t *testing.T) {
+	policyDir := filepath.Join(testDataDir, "policy")
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		fName         string
+		expPreRelPats int
+		expMaxPreRels int
+		expForbidden  int
+		expForbidV0   bool
+		expCheckSeq   bool
+		expMaxJump    int
+	}{
+		{
+			ID:            testhelper.MkID("good"),
+			fName:         filepath.Join(policyDir, "good.pol"),
+			expPreRelPats: 2,
+			expMaxPreRels: 2,
+			expForbidden:  1,
+			expForbidV0:   true,
+			expCheckSeq:   true,
+			expMaxJump:    2,
+		},
+		{
+			ID:    testhelper.MkID("bad"),
+			fName: filepath.Join(policyDir, "bad.pol"),
+			ExpErr: testhelper.MkExpErr(
+				`bad value for the "max-pre-releases" rule: bad number`,
+				`unknown policy rule: "no-such-rule"`,
+				`bad value for the "allow-v0" rule`),
+		},
+		{
+			ID:     testhelper.MkID("missing"),
+			fName:  filepath.Join(policyDir, "nonesuch.pol"),
+			ExpErr: testhelper.MkExpErr("no such file or directory"),
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+
+		err := prog.readPolicy(tc.fName)
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		testhelper.DiffInt(t, tc.IDStr(), "pre-release ID patterns",
+			len(prog.policy.preRelIDPats), tc.expPreRelPats)
+		testhelper.DiffInt(t, tc.IDStr(), "max pre-releases",
+			prog.policy.maxPreRels, tc.expMaxPreRels)
+		testhelper.DiffInt(t, tc.IDStr(), "forbidden",
+			len(prog.policy.forbidden), tc.expForbidden)
+		testhelper.DiffBool(t, tc.IDStr(), "forbid v0",
+			prog.policy.forbidV0, tc.expForbidV0)
+		testhelper.DiffBool(t, tc.IDStr(), "check seq",
+			prog.checkSeq, tc.expCheckSeq)
+		testhelper.DiffInt(t, tc.IDStr(), "max jump",
+			prog.maxJump, tc.expMaxJump)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	prog := newProg()
+	if err := prog.readPolicy(
+		filepath.Join(testDataDir, "policy", "good.pol")); err != nil {
+		t.Fatal("unexpected error reading the policy:", err)
+	}
+
+	fio, err := testhelper.NewStdioFromString(
+		"v0.9.0\n" +
+			"v1.0.0\n" +
+			"v1.2.0\n" +
+			"v1.3.0-alpha1\n" +
+			"v1.3.0-beta1\n" +
+			"v1.3.0-rc1\n" +
+			"v1.3.0\n" +
+			"v1.4.0-pre\n" +
+			"v1.4.0+b1\n" +
+			"v1.6.0\n")
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	prog.checkList(prog.getSVsFromStdin())
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	gfc.Check(t, "policy", "policy", stdout)
+	testhelper.DiffInt(t, "policy", "exit status", prog.exitStatus, 1)
+}
+
+func TestPolicyParamPrecedence(t *testing.T) {
+	policyFile := filepath.Join(testDataDir, "policy", "good.pol")
+
+	testCases := []struct {
+		testhelper.ID
+		args        []string
+		expCheckSeq bool
+		expMaxJump  int
+	}{
+		{
+			ID:          testhelper.MkID("policy only"),
+			args:        []string{"-policy", policyFile},
+			expCheckSeq: true,
+			expMaxJump:  2,
+		},
+		{
+			ID:          testhelper.MkID("params after the policy"),
+			args:        []string{"-policy", policyFile, "-max-jump", "5"},
+			expCheckSeq: true,
+			expMaxJump:  5,
+		},
+		{
+			ID: testhelper.MkID("params before the policy"),
+			args: []string{
+				"-max-jump", "5", "-check-seq=false", "-policy", policyFile,
+			},
+			expCheckSeq: false,
+			expMaxJump:  5,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		ps := makeParamSet(prog)
+
+		ps.Parse(tc.args)
+
+		if err := prog.readPolicy(prog.policyFile); err != nil {
+			t.Fatal(tc.IDStr(), ": unexpected error reading the policy:", err)
+		}
+
+		testhelper.DiffBool(t, tc.IDStr(), "check seq",
+			prog.checkSeq, tc.expCheckSeq)
+		testhelper.DiffInt(t, tc.IDStr(), "max jump",
+			prog.maxJump, tc.expMaxJump)
+	}
+}
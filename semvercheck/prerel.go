@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/prid"
+)
+
+// vsnStr returns the semver without any pre-release or build IDs
+func vsnStr(sv *semver.SV) string {
+	return fmt.Sprintf("v%d.%d.%d", sv.Major(), sv.Minor(), sv.Patch())
+}
+
+// chkNewVsnPreRel checks the pre-release IDs where the second entry has a
+// different major, minor or patch version from the first. Any sequence of
+// pre-releases of the first must have ended in a release and, if a ladder
+// of pre-release stages has been given, the second must be the first
+// pre-release of the first stage (such as 'alpha.1').
+func (prog *prog) chkNewVsnPreRel(e1, e2 *entry) {
+	if e1.sv.HasPreRelIDs() {
+		prog.reportSeqErr(e1, e2, probOther,
+			"the pre-release sequence for "+vsnStr(e1.sv)+
+				" does not end in a release")
+	}
+
+	if !e2.sv.HasPreRelIDs() || len(prog.preRelLadder) == 0 {
+		return
+	}
+
+	prIDs := e2.sv.PreRelIDs()
+
+	if stage := prid.Stage(prIDs); stage != prog.preRelLadder[0] {
+		prog.reportSeqErr(e1, e2, probOther,
+			fmt.Sprintf("the pre-release sequence for %s"+
+				" starts at stage %q (should be %q)",
+				vsnStr(e2.sv), stage, prog.preRelLadder[0]))
+
+		return
+	}
+
+	expIDs := prid.FirstOfStage(prIDs, prog.preRelLadder[0])
+	if !slices.Equal(expIDs, prIDs) {
+		prog.reportSeqErr(e1, e2, probGap,
+			fmt.Sprintf("the pre-release sequence for %s"+
+				" starts at %q (should be %q)",
+				vsnStr(e2.sv), strings.Join(prIDs, "."),
+				strings.Join(expIDs, ".")))
+	}
+}
+
+// chkPreRelProgression checks the pre-release IDs where the two entries
+// have the same major, minor and patch versions and the second is greater
+// than the first. If a ladder of pre-release stages has been given then the
+// stages must be on the ladder and they may only advance by one stage at a
+// time, starting at the first pre-release of the new stage (so 'alpha.3'
+// must be followed by 'alpha.4' or 'beta.1'). Within a stage the numeric
+// part of the last pre-release ID must increase by one.
+func (prog *prog) chkPreRelProgression(e1, e2 *entry) {
+	prIDs1, prIDs2 := e1.sv.PreRelIDs(), e2.sv.PreRelIDs()
+	if len(prIDs1) == 0 || len(prIDs2) == 0 {
+		return
+	}
+
+	stage1, stage2 := prid.Stage(prIDs1), prid.Stage(prIDs2)
+
+	if len(prog.preRelLadder) > 0 {
+		rung1 := slices.Index(prog.preRelLadder, stage1)
+		rung2 := slices.Index(prog.preRelLadder, stage2)
+
+		switch {
+		case rung2 < 0:
+			prog.reportSeqErr(e1, e2, probOther,
+				fmt.Sprintf("the pre-release stage %q is not one of: %s",
+					stage2, strings.Join(prog.preRelLadder, ", ")))
+
+			return
+		case rung1 < 0:
+			return
+		case rung2 < rung1:
+			prog.reportSeqErr(e1, e2, probOrder,
+				fmt.Sprintf("the pre-release stages are out of order:"+
+					" %q should come before %q", stage2, stage1))
+
+			return
+		case rung2 > rung1+1:
+			prog.reportSeqErr(e1, e2, probGap,
+				fmt.Sprintf("the pre-release stages have gaps:"+
+					" %q is followed by %q (should be %q)",
+					stage1, stage2, prog.preRelLadder[rung1+1]))
+
+			return
+		case rung2 == rung1+1:
+			prog.chkFirstOfStage(e1, e2)
+			return
+		}
+	} else if stage1 != stage2 {
+		return
+	}
+
+	expIDs, err := prid.IncrLast(prIDs1)
+	if err != nil {
+		return
+	}
+
+	if !slices.Equal(expIDs, prIDs2) {
+		prog.reportSeqErr(e1, e2, probGap,
+			fmt.Sprintf("the pre-release IDs have gaps:"+
+				" the next pre-release ID should be %q",
+				strings.Join(expIDs, ".")))
+	}
+}
+
+// chkFirstOfStage checks that the second entry, which is at the stage
+// after that of the first, is the first pre-release of that stage.
+func (prog *prog) chkFirstOfStage(e1, e2 *entry) {
+	prIDs2 := e2.sv.PreRelIDs()
+
+	expIDs, err := prid.NextStage(e1.sv.PreRelIDs(), prog.preRelLadder)
+	if err != nil || slices.Equal(expIDs, prIDs2) {
+		return
+	}
+
+	prog.reportSeqErr(e1, e2, probGap,
+		fmt.Sprintf("the pre-release IDs have gaps:"+
+			" the first pre-release of stage %q should be %q",
+			prid.Stage(prIDs2), strings.Join(expIDs, ".")))
+}
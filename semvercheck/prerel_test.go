@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestPreRelSeq(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		ladder        []string
+		expExitStatus int
+	}{
+		{
+			ID: testhelper.MkID("good-noLadder"),
+			input: "v1.0.0-rc.1\nv1.0.0-rc.2\nv1.0.0\n" +
+				"v1.0.1-RC009\nv1.0.1-RC010\nv1.0.1\n",
+		},
+		{
+			ID: testhelper.MkID("good-ladder"),
+			input: "v1.0.0-alpha.1\nv1.0.0-beta.1\nv1.0.0-beta.2\n" +
+				"v1.0.0-rc.1\nv1.0.0\nv1.0.1\n",
+			ladder: []string{"alpha", "beta", "rc"},
+		},
+		{
+			ID:            testhelper.MkID("bad-noLadder"),
+			input:         "v1.0.0-rc.1\nv1.0.0-rc.3\nv1.1.0\n",
+			expExitStatus: 1,
+		},
+		{
+			ID: testhelper.MkID("bad-ladder"),
+			input: "v1.0.0-beta.1\nv1.0.0-beta.2\nv1.0.0-gamma.1\n" +
+				"v1.0.0\nv1.1.0-alpha.1\nv1.1.0-rc.1\n",
+			ladder:        []string{"alpha", "beta", "rc"},
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("bad-ladder-newVsnNumber"),
+			input:         "v1.0.0\nv1.1.0-alpha.7\nv1.1.0-alpha.8\n",
+			ladder:        []string{"alpha", "beta", "rc"},
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("bad-ladder-newStageNumber"),
+			input:         "v1.0.0-alpha.1\nv1.0.0-alpha.2\nv1.0.0-beta.5\n",
+			ladder:        []string{"alpha", "beta", "rc"},
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("bad-ladder-newStageForm"),
+			input:         "v1.0.0-alpha1\nv1.0.0-beta.1\n",
+			ladder:        []string{"alpha", "beta", "rc"},
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.chkPreRelSeq = true
+		prog.preRelLadder = tc.ladder
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.seqCheck(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "preRelSeq."+tc.Name, stdout)
+
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
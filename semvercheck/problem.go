@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// problem is a category of problem found by the checks. Each category is
+// a distinct bit so that a set of problems can be recorded in a single
+// value. The lowest bit is not used so that an exit status of 1 always
+// means a general failure such as bad parameters or unreadable input.
+type problem int
+
+const (
+	probParse problem = 1 << (iota + 1)
+	probIDRule
+	probOrder
+	probGap
+	probDup
+	probOther
+	probPolicy
+	probChangelog
+	probAPI
+)
+
+// problems lists the problem categories in order of precedence. When the
+// exit status is the code of a single category it is the code of the first
+// of these which has been found.
+var problems = []problem{
+	probParse,
+	probIDRule,
+	probOrder,
+	probGap,
+	probDup,
+	probOther,
+	probPolicy,
+	probChangelog,
+	probAPI,
+}
+
+// the names of the problem categories
+const (
+	probNameParse  = "parse"
+	probNameIDRule = "id-rule"
+	probNameOrder  = "order"
+	probNameGap    = "gap"
+	probNameDup    = "duplicate"
+	probNameOther  = "other"
+	probNamePolicy = "policy"
+	probNameChlog  = "changelog"
+	probNameAPI    = "api"
+)
+
+// String returns the name of the problem category
+func (p problem) String() string {
+	switch p {
+	case probParse:
+		return probNameParse
+	case probIDRule:
+		return probNameIDRule
+	case probOrder:
+		return probNameOrder
+	case probGap:
+		return probNameGap
+	case probDup:
+		return probNameDup
+	case probOther:
+		return probNameOther
+	case probPolicy:
+		return probNamePolicy
+	case probChangelog:
+		return probNameChlog
+	case probAPI:
+		return probNameAPI
+	}
+
+	return fmt.Sprintf("unknown problem: %d", int(p))
+}
+
+// desc returns a description of the problem category
+func (p problem) desc() string {
+	switch p {
+	case probParse:
+		return "a value could not be parsed as a semantic version ID"
+	case probIDRule:
+		return "the pre-release or build IDs broke the ID rules"
+	case probOrder:
+		return "the sequence is out of order"
+	case probGap:
+		return "the sequence has gaps"
+	case probDup:
+		return "the sequence has duplicate entries"
+	case probOther:
+		return "any other check failed (such as the pre-release" +
+			" stages, the release chronology or the module path)"
+	case probPolicy:
+		return "a rule in the policy file was broken"
+	case probChangelog:
+		return "the changelog does not match the versions"
+	case probAPI:
+		return "the version change is too small for the API changes"
+	}
+
+	return p.String()
+}
+
+// exitBit returns the bit set in the exit status for the problem category
+// when the exit status is a bitmask. There are not enough bits in an exit
+// status for a bit per category and so the changelog and API categories
+// share the bit of the 'other' category.
+func (p problem) exitBit() int {
+	switch p {
+	case probChangelog, probAPI:
+		return int(probOther)
+	}
+
+	return int(p)
+}
+
+// categoryCode returns the exit status used for the problem category when
+// the exit status is set by category. These follow on from 1 in order of
+// precedence.
+func (p problem) categoryCode() int {
+	for i, cp := range problems {
+		if cp == p {
+			return i + 2 //nolint:mnd
+		}
+	}
+
+	return 1
+}
+
+const (
+	exitModeSimple   = "simple"
+	exitModeCategory = "category"
+	exitModeBitmask  = "bitmask"
+)
+
+// exitCodesDesc returns a description of the exit statuses for the exit
+// mode
+func exitCodesDesc(mode string) string {
+	codes := []int{}
+	descs := map[int][]string{}
+
+	for _, p := range problems {
+		code := p.categoryCode()
+		if mode == exitModeBitmask {
+			code = p.exitBit()
+		}
+
+		if _, ok := descs[code]; !ok {
+			codes = append(codes, code)
+		}
+
+		descs[code] = append(descs[code], p.desc())
+	}
+
+	parts := []string{}
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%d if %s",
+			code, strings.Join(descs[code], " or ")))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// fail records that a problem of the given category has been found and
+// sets the exit status accordingly
+func (prog *prog) fail(p problem) {
+	prog.problems |= p
+
+	switch prog.exitMode {
+	case exitModeBitmask:
+		prog.exitStatus = 0
+
+		for _, cp := range problems {
+			if prog.problems&cp != 0 {
+				prog.exitStatus |= cp.exitBit()
+			}
+		}
+	case exitModeCategory:
+		for _, cp := range problems {
+			if prog.problems&cp != 0 {
+				prog.exitStatus = cp.categoryCode()
+				break
+			}
+		}
+	default:
+		prog.exitStatus = 1
+	}
+}
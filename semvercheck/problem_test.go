@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestExitStatus(t *testing.T) {
+	const input = "v1.0.0\nbad\nv1.2.0\nv1.2.0\n"
+
+	testCases := []struct {
+		testhelper.ID
+		exitMode      string
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("simple"),
+			exitMode:      exitModeSimple,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("category"),
+			exitMode:      exitModeCategory,
+			expExitStatus: 2,
+		},
+		{
+			ID:            testhelper.MkID("bitmask"),
+			exitMode:      exitModeBitmask,
+			expExitStatus: int(probParse | probGap | probDup),
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.exitMode = tc.exitMode
+		prog.checkSeq = true
+
+		fio, err := testhelper.NewStdioFromString(input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.checkList(prog.getSVsFromStdin())
+
+		if _, _, err = fio.Done(); err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
+
+func TestCategoryCode(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		p       problem
+		expCode int
+	}{
+		{ID: testhelper.MkID("parse"), p: probParse, expCode: 2},
+		{ID: testhelper.MkID("id-rule"), p: probIDRule, expCode: 3},
+		{ID: testhelper.MkID("order"), p: probOrder, expCode: 4},
+		{ID: testhelper.MkID("gap"), p: probGap, expCode: 5},
+		{ID: testhelper.MkID("duplicate"), p: probDup, expCode: 6},
+		{ID: testhelper.MkID("other"), p: probOther, expCode: 7},
+		{ID: testhelper.MkID("policy"), p: probPolicy, expCode: 8},
+		{ID: testhelper.MkID("changelog"), p: probChangelog, expCode: 9},
+		{ID: testhelper.MkID("api"), p: probAPI, expCode: 10},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffInt(t, tc.IDStr(), "code",
+			tc.p.categoryCode(), tc.expCode)
+	}
+}
+
+// maxExitStatus is the largest exit status which a program can return. The
+// exit status is truncated to this many bits by the operating system.
+const maxExitStatus = 0xff
+
+func TestExitBit(t *testing.T) {
+	for _, p := range problems {
+		if bit := p.exitBit(); bit <= 1 || bit > maxExitStatus {
+			t.Errorf("the exit bit for the %q category (%d)"+
+				" is not in the range [2, %d]", p, bit, maxExitStatus)
+		}
+	}
+}
+
+// envMainArgs is the name of the environment variable which causes the
+// test program to run main with the arguments it holds, one per line
+const envMainArgs = "SEMVERCHECK_TEST_MAIN_ARGS"
+
+// TestExitStatusFromOS checks the exit status as seen by the operating
+// system by running the test program as a sub-process which calls main.
+func TestExitStatusFromOS(t *testing.T) {
+	if args, ok := os.LookupEnv(envMainArgs); ok {
+		os.Args = append(os.Args[:1], strings.Split(args, "\n")...)
+		main()
+
+		return
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		input         string
+		args          []string
+		expExitStatus int
+	}{
+		{
+			ID:    testhelper.MkID("changelog"),
+			input: "v1.0.0\nv1.0.1\nv1.1.0\nv1.2.0\n",
+			args: []string{
+				"-changelog",
+				filepath.Join(testDataDir, "changelog", "CHANGELOG.md"),
+			},
+			expExitStatus: probChangelog.exitBit(),
+		},
+		{
+			ID:    testhelper.MkID("api"),
+			input: "v1.0.0\nv1.1.0\n",
+			args: []string{
+				"-api-old", filepath.Join(testDataDir, "api", "old"),
+				"-api-new", filepath.Join(testDataDir, "api", "new"),
+			},
+			expExitStatus: probAPI.exitBit(),
+		},
+		{
+			ID:            testhelper.MkID("parse and duplicate"),
+			input:         "v1.0.0\nbad\nv1.0.0\n",
+			args:          []string{"-check-dups"},
+			expExitStatus: probParse.exitBit() | probDup.exitBit(),
+		},
+	}
+
+	for _, tc := range testCases {
+		args := append([]string{"-exit-status", exitModeBitmask}, tc.args...)
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestExitStatusFromOS$")
+		cmd.Env = append(os.Environ(),
+			envMainArgs+"="+strings.Join(args, "\n"))
+		cmd.Stdin = strings.NewReader(tc.input)
+
+		exitStatus := 0
+
+		err := cmd.Run()
+		if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) {
+			exitStatus = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot run the test program:", err)
+		}
+
+		testhelper.DiffInt(t, tc.IDStr(), "exit status",
+			exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+const (
+	seqByAll   = "all"
+	seqByMajor = "major"
+	seqByMinor = "minor"
+)
+
+// releaseLine returns the name of the release line to which the semver
+// belongs according to the seqBy parameter. If the semvers are not being
+// grouped then the empty string is returned.
+func (prog *prog) releaseLine(sv *semver.SV) string {
+	switch prog.seqBy {
+	case seqByMajor:
+		return fmt.Sprintf("v%d.x", sv.Major())
+	case seqByMinor:
+		return fmt.Sprintf("v%d.%d.x", sv.Major(), sv.Minor())
+	}
+
+	return ""
+}
+
+// groupByReleaseLine splits the entries into groups, one per release line,
+// preserving the order in which the entries appear. The groups are returned
+// in the order in which each release line was first seen. Entries without
+// a valid semver are not included in any group.
+func (prog *prog) groupByReleaseLine(el []*entry) [][]*entry {
+	groups := [][]*entry{}
+	groupIdx := map[string]int{}
+
+	for _, e := range el {
+		if e.sv == nil {
+			continue
+		}
+
+		line := prog.releaseLine(e.sv)
+
+		idx, ok := groupIdx[line]
+		if !ok {
+			idx = len(groups)
+			groupIdx[line] = idx
+			groups = append(groups, []*entry{})
+		}
+
+		groups[idx] = append(groups[idx], e)
+	}
+
+	return groups
+}
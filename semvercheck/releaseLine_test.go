@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSeqCheckByReleaseLine(t *testing.T) {
+	const input = "v1.4.0\nv2.0.0\nv1.4.1\nv2.0.1\nv1.4.3\nv1.5.0\nv2.1.0\n"
+
+	testCases := []struct {
+		testhelper.ID
+		seqBy         string
+		expExitStatus int
+	}{
+		{
+			ID:            testhelper.MkID("all"),
+			seqBy:         seqByAll,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("major"),
+			seqBy:         seqByMajor,
+			expExitStatus: 1,
+		},
+		{
+			ID:            testhelper.MkID("minor"),
+			seqBy:         seqByMinor,
+			expExitStatus: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.seqBy = tc.seqBy
+
+		fio, err := testhelper.NewStdioFromString(input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		prog.seqCheck(prog.getSVsFromStdin())
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "seqBy."+tc.Name, stdout)
+
+		testhelper.DiffInt(t,
+			tc.IDStr(), "exit status",
+			prog.exitStatus, tc.expExitStatus)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nickwells/location.mod/location"
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+const (
+	fmtText  = "text"
+	fmtJSON  = "json"
+	fmtSARIF = "sarif"
+)
+
+// seqErr records a problem found when an entry is compared with its
+// predecessor in the list of semvers
+type seqErr struct {
+	PrevIdx int      `json:"prevIndex"`
+	Prev    string   `json:"prev"`
+	Idx     int      `json:"index"`
+	Kind    string   `json:"kind"`
+	Msg     string   `json:"message"`
+	Missing []string `json:"missing,omitempty"`
+
+	APIChanges []string `json:"apiChanges,omitempty"`
+
+	ReleaseLine string `json:"releaseLine,omitempty"`
+
+	kind      problem
+	prevLoc   location.L
+	isGap     bool
+	allListed bool
+}
+
+// entry records a single value read, the semver made from it (if it could be
+// parsed) and any problems found with it
+type entry struct {
+	idx      int
+	loc      location.L
+	module   string
+	svStr    string
+	svOffset int
+	date     time.Time
+	sv       *semver.SV
+
+	parseErr      error
+	suggestion    string
+	idErr         error
+	modErr        error
+	policyErrs    []error
+	changelogErrs []error
+	apiErr        error
+	retracted     bool
+	warnings      []string
+	seqErrs       []seqErr
+
+	skipped      []location.L
+	skippedAfter []location.L
+}
+
+// newEntry returns a new entry for the location, which must have content
+func newEntry(loc *location.L, idx int) *entry {
+	s, hasContent := loc.Content()
+	if !hasContent {
+		panic(fmt.Errorf(
+			"program error: the location should have content: %s", loc))
+	}
+
+	return &entry{idx: idx, loc: *loc, svStr: s}
+}
+
+// locStr returns the location for reporting. A location with no line
+// number, such as that of a git tag, is given by its source alone.
+func locStr(loc location.L) string {
+	if loc.Idx() == 0 {
+		return loc.Source()
+	}
+
+	return loc.String()
+}
+
+// input returns the string the entry was made from
+func (e entry) input() string {
+	s, _ := e.loc.Content()
+	return s
+}
+
+// jsonRecord is the form in which an entry is reported when the output
+// format is JSON
+type jsonRecord struct {
+	Source    string   `json:"source"`
+	Line      int64    `json:"line,omitempty"`
+	Index     int      `json:"index"`
+	Module    string   `json:"module,omitempty"`
+	Input     string   `json:"input"`
+	Semver    string   `json:"semver,omitempty"`
+	Date      string   `json:"date,omitempty"`
+	OK        bool     `json:"ok"`
+	ParseErr  string   `json:"parseError,omitempty"`
+	Suggest   string   `json:"suggestion,omitempty"`
+	IDErr     string   `json:"idError,omitempty"`
+	ModErr    string   `json:"modulePathError,omitempty"`
+	PolErrs   []string `json:"policyErrors,omitempty"`
+	ChlogErrs []string `json:"changelogErrors,omitempty"`
+	APIErr    string   `json:"apiError,omitempty"`
+	Retract   bool     `json:"retracted,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	SeqErrs   []seqErr `json:"sequenceErrors,omitempty"`
+}
+
+// errStr returns the error message or the empty string if err is nil
+func errStr(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// jsonRecord converts the entry into a jsonRecord
+func (e entry) jsonRecord() jsonRecord {
+	rec := jsonRecord{
+		Source:   e.loc.Source(),
+		Line:     e.loc.Idx(),
+		Index:    e.idx,
+		Module:   e.module,
+		Input:    e.input(),
+		Date:     e.dateStr(),
+		ParseErr: errStr(e.parseErr),
+		Suggest:  e.suggestion,
+		IDErr:    errStr(e.idErr),
+		ModErr:   errStr(e.modErr),
+		APIErr:   errStr(e.apiErr),
+		Retract:  e.retracted,
+		Warnings: e.warnings,
+		SeqErrs:  e.seqErrs,
+	}
+	if e.sv != nil {
+		rec.Semver = e.sv.String()
+	}
+
+	for _, err := range e.policyErrs {
+		rec.PolErrs = append(rec.PolErrs, err.Error())
+	}
+
+	for _, err := range e.changelogErrs {
+		rec.ChlogErrs = append(rec.ChlogErrs, err.Error())
+	}
+
+	rec.OK = e.parseErr == nil && e.idErr == nil && e.modErr == nil &&
+		e.apiErr == nil &&
+		len(e.policyErrs) == 0 && len(e.changelogErrs) == 0 &&
+		len(e.seqErrs) == 0
+
+	return rec
+}
+
+// rptOut returns the writer to which the report should be written. This is
+// normally the standard output but if the corrected values are being
+// printed it is the standard error.
+func (prog *prog) rptOut() io.Writer {
+	if prog.fix {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// reportSVErr reports a problem found with the semver of a single entry
+func (prog *prog) reportSVErr(e *entry, err error) {
+	if prog.format != fmtText {
+		return
+	}
+
+	w := prog.rptOut()
+
+	fmt.Fprintln(w, locStr(e.loc))
+	fmt.Fprintln(w, "   ", err)
+
+	if e.suggestion != "" {
+		fmt.Fprintln(w, "    did you mean:", e.suggestion)
+	}
+}
+
+// reportWarning records the warning against the entry and reports it. A
+// warning does not change the exit status.
+func (prog *prog) reportWarning(e *entry, msg string) {
+	e.warnings = append(e.warnings, msg)
+
+	if prog.format != fmtText {
+		return
+	}
+
+	w := prog.rptOut()
+
+	fmt.Fprintln(w, locStr(e.loc))
+	fmt.Fprintln(w, "    warning:", msg)
+}
+
+// report writes out the collected entries if the output format requires
+// it. The text format is reported as the problems are found and so nothing
+// more is done here. The SARIF format is reported as a single log once all
+// the lists have been checked and so the results are only collected here.
+func (prog *prog) report(el []*entry) {
+	switch prog.format {
+	case fmtJSON:
+		prog.writeJSON(el)
+	case fmtSARIF:
+		prog.addSARIFResults(el)
+	}
+}
+
+// finishReport writes out anything remaining to be reported once all the
+// lists have been checked
+func (prog *prog) finishReport() {
+	if prog.changelogFile != "" {
+		prog.chkChangelogHeadings()
+	}
+
+	if prog.byModule {
+		prog.printModuleSummary()
+	}
+
+	if prog.format == fmtSARIF {
+		prog.writeSARIFLog()
+	}
+}
+
+// writeJSON writes out the entries as JSON records, one per line
+func (prog *prog) writeJSON(el []*entry) {
+	enc := json.NewEncoder(prog.rptOut())
+	enc.SetEscapeHTML(false)
+
+	for _, e := range el {
+		if err := enc.Encode(e.jsonRecord()); err != nil {
+			fmt.Fprintln(os.Stderr, "cannot write the JSON record:", err)
+			prog.exitStatus = 1
+
+			return
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+// These constants describe the SARIF log written
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/nickwells/semvertools"
+	sarifLevel   = "error"
+	sarifWarning = "warning"
+)
+
+// sarifLog is the top-level object of a SARIF log
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun records the results of a single run of the tool
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool which produced the results
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver describes the tool and the rules that it checks
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes one of the rules checked; there is one rule for each
+// category of problem
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+// sarifMessage holds the text of a message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult records a single problem found
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+// sarifLocation records where a problem was found. Values read from files
+// are given a physical location; other values, such as those read from the
+// standard input or from git tags, are given a logical location.
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+// sarifPhysicalLocation gives the file and line of a problem
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifArtifactLocation gives the file
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion gives the line in the file
+type sarifRegion struct {
+	StartLine int64 `json:"startLine"`
+}
+
+// sarifLogicalLocation names the source and position of the value
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// sarifRuleIndex returns the index of the rule for the problem category
+func sarifRuleIndex(p problem) int {
+	for i, cp := range problems {
+		if cp == p {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sarifRules returns the rules, one for each problem category
+func sarifRules() []sarifRule {
+	rules := make([]sarifRule, 0, len(problems))
+	for _, p := range problems {
+		rules = append(rules, sarifRule{
+			ID:               p.String(),
+			ShortDescription: sarifMessage{Text: p.desc()},
+		})
+	}
+
+	return rules
+}
+
+// sarifLocation returns the SARIF form of the location of a value. The
+// headings of the changelog always have a physical location.
+func (prog *prog) sarifLocation(loc location.L) sarifLocation {
+	if len(prog.files) > 0 ||
+		(prog.changelogFile != "" && loc.Source() == prog.changelogFile) {
+		return sarifLocation{
+			PhysicalLocation: &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{
+					URI: filepath.ToSlash(loc.Source()),
+				},
+				Region: sarifRegion{StartLine: loc.Idx()},
+			},
+		}
+	}
+
+	content, _ := loc.Content()
+
+	name := loc.Source()
+	if loc.Idx() > 0 {
+		name = fmt.Sprintf("%s:%d", loc.Source(), loc.Idx())
+	}
+
+	return sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{
+			{
+				Name:               content,
+				FullyQualifiedName: name,
+			},
+		},
+	}
+}
+
+// sarifResult returns a result for the problem found with the entry
+func (prog *prog) sarifResult(e *entry, p problem, msg string) sarifResult {
+	return sarifResult{
+		RuleID:    p.String(),
+		RuleIndex: sarifRuleIndex(p),
+		Level:     sarifLevel,
+		Message:   sarifMessage{Text: msg},
+		Locations: []sarifLocation{prog.sarifLocation(e.loc)},
+	}
+}
+
+// addSARIFResults adds a result for every problem found with the entries
+// in the list
+func (prog *prog) addSARIFResults(el []*entry) {
+	for _, e := range el {
+		if e.parseErr != nil {
+			msg := e.parseErr.Error()
+			if e.suggestion != "" {
+				msg += " (did you mean: " + e.suggestion + ")"
+			}
+
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probParse, msg))
+		}
+
+		if e.idErr != nil {
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probIDRule, e.idErr.Error()))
+		}
+
+		if e.modErr != nil {
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probOther, e.modErr.Error()))
+		}
+
+		if e.apiErr != nil {
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probAPI, e.apiErr.Error()))
+		}
+
+		prog.addSARIFWarnings(e)
+
+		for _, err := range e.policyErrs {
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probPolicy, err.Error()))
+		}
+
+		for _, err := range e.changelogErrs {
+			prog.sarifResults = append(prog.sarifResults,
+				prog.sarifResult(e, probChangelog, err.Error()))
+		}
+
+		for _, se := range e.seqErrs {
+			msg := se.Msg
+			if se.isGap {
+				msg += " - " + missingMsg(se.Missing, se.allListed)
+			}
+
+			if len(se.APIChanges) > 0 {
+				msg += " - " + strings.Join(se.APIChanges, ", ")
+			}
+
+			r := prog.sarifResult(e, se.kind, msg)
+
+			prevLoc := prog.sarifLocation(se.prevLoc)
+			prevLoc.ID = 1
+			prevLoc.Message = &sarifMessage{Text: "the preceding value: " +
+				se.Prev}
+			r.RelatedLocations = []sarifLocation{prevLoc}
+
+			prog.sarifResults = append(prog.sarifResults, r)
+		}
+	}
+}
+
+// addSARIFWarnings adds a result for every warning given for the entry
+func (prog *prog) addSARIFWarnings(e *entry) {
+	for _, msg := range e.warnings {
+		r := prog.sarifResult(e, probOther, msg)
+		r.Level = sarifWarning
+		prog.sarifResults = append(prog.sarifResults, r)
+	}
+}
+
+// writeSARIFLog writes the SARIF log containing all the results found
+func (prog *prog) writeSARIFLog() {
+	results := prog.sarifResults
+	if results == nil {
+		results = []sarifResult{}
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "semvercheck",
+						InformationURI: sarifToolURI,
+						Rules:          sarifRules(),
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	enc := json.NewEncoder(prog.rptOut())
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(log); err != nil {
+		fmt.Fprintln(os.Stderr, "cannot write the SARIF log:", err)
+		prog.exitStatus = 1
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestSARIFReport(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input      string
+		files      []string
+		expResults int
+	}{
+		{
+			ID:    testhelper.MkID("stdin"),
+			input: "v1.0.0\nv1.0.2\nbad\nv1.0.2\n",
+			// a gap, a parse error and a duplicate
+			expResults: 3,
+		},
+		{
+			ID:    testhelper.MkID("files"),
+			files: []string{filepath.Join(testDataDir, "files", "*.txt")},
+			// a gap in a.txt, a duplicate and a parse error in b.txt
+			expResults: 3,
+		},
+		{
+			ID:    testhelper.MkID("good"),
+			input: "v1.0.0\nv1.0.1\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		prog := newProg()
+		prog.format = fmtSARIF
+		prog.checkSeq = true
+		prog.files = tc.files
+
+		fio, err := testhelper.NewStdioFromString(tc.input)
+		if err != nil {
+			t.Error("unexpected error faking IO", err)
+			continue
+		}
+
+		for _, el := range prog.getEntryLists(nil) {
+			prog.checkList(el)
+		}
+
+		prog.finishReport()
+
+		stdout, _, err := fio.Done()
+		if err != nil {
+			t.Error("unexpected error retrieving stdout and stderr", err)
+			continue
+		}
+
+		gfc.Check(t, tc.IDStr(), "sarif."+tc.Name, stdout)
+
+		var log sarifLog
+		if err := json.Unmarshal(stdout, &log); err != nil {
+			t.Log(tc.IDStr())
+			t.Error("\t: the SARIF log is not valid JSON:", err)
+
+			continue
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "version",
+			log.Version, sarifVersion)
+
+		if len(log.Runs) != 1 {
+			t.Log(tc.IDStr())
+			t.Errorf("\t: expected 1 run, got %d", len(log.Runs))
+
+			continue
+		}
+
+		testhelper.DiffInt(t, tc.IDStr(), "results",
+			len(log.Runs[0].Results), tc.expResults)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nickwells/location.mod/location"
+)
+
+// streamState records what is needed from the entries already seen in
+// order to check the next entry of a streamed list. Only the latest entry
+// in each module's release lines and the highest entry are kept so the
+// memory used does not grow with the length of the list. The exception is
+// that if the policy limits the number of pre-releases then a count is kept
+// for each version having pre-releases.
+type streamState struct {
+	prev        map[string]*entry
+	chronoPrev  map[string]*entry
+	preRelCount map[string]int
+	highest     *entry
+}
+
+// newStreamState returns a new streamState ready to check a list
+func newStreamState() *streamState {
+	return &streamState{
+		prev:        map[string]*entry{},
+		chronoPrev:  map[string]*entry{},
+		preRelCount: map[string]int{},
+	}
+}
+
+// streamEntry performs the checks on the entry as soon as it has been read
+// and reports the results. The checks and their results are the same as
+// when the whole list is checked at once.
+func (prog *prog) streamEntry(st *streamState, e *entry) {
+	el := []*entry{e}
+
+	if prog.byModule {
+		prog.chkModuleDirs(el)
+	}
+
+	prog.chkModPath(el)
+	st.highest = prog.markRetracted(e, st.highest)
+
+	prog.chkPolicyEntry(e, st.preRelCount)
+
+	if prog.changelogFile != "" {
+		prog.chkChangelog(el)
+	}
+
+	if prog.checkSeq && e.sv != nil {
+		line := e.module + " " + prog.releaseLine(e.sv)
+		if prev := st.prev[line]; prev != nil {
+			prog.chkSequence(prev, e)
+		}
+
+		st.prev[line] = e
+	}
+
+	if prog.chkChrono && e.sv != nil {
+		line := chronoLine(e.module, e.sv)
+		if prev := st.chronoPrev[line]; prev != nil {
+			prog.chkChronology(prev, e)
+		}
+
+		st.chronoPrev[line] = e
+	}
+
+	if prog.fillGaps {
+		prog.printFilledSeq(el)
+	}
+
+	if prog.fix {
+		prog.printFixed(el)
+	}
+
+	prog.report(el)
+
+	if prog.byModule {
+		prog.addToSummary(e)
+	}
+}
+
+// streamEnd performs any checks which can only be done once the whole
+// list has been read. The JSON record of each entry has already been
+// written and so any warning is also written to the standard error.
+func (prog *prog) streamEnd(st *streamState) {
+	if !prog.warnIfRetracted(st.highest) {
+		return
+	}
+
+	switch prog.format {
+	case fmtJSON:
+		fmt.Fprintf(os.Stderr, "%s: warning: %s\n",
+			locStr(st.highest.loc), strings.Join(st.highest.warnings, ", "))
+	case fmtSARIF:
+		prog.addSARIFWarnings(st.highest)
+	}
+}
+
+// streamReader reads the semvers from the reader, checking and reporting
+// on each as soon as it is read
+func (prog *prog) streamReader(r io.Reader, name string) error {
+	st := newStreamState()
+
+	skipped, err := prog.readEntries(r, name,
+		func(e *entry) { prog.streamEntry(st, e) })
+
+	printSkipped(skipped)
+
+	prog.streamEnd(st)
+
+	return err
+}
+
+// streamLists reads and checks the semvers from the chosen source, checking
+// and reporting on each as soon as it is read. Each file is checked as a
+// separate list. On any failure to read the source it reports the problem
+// and exits.
+func (prog *prog) streamLists(cmdLineSVs []string) {
+	prog.chkSources(cmdLineSVs)
+
+	switch {
+	case len(prog.files) > 0:
+		files, err := expandFiles(prog.files)
+		exitOnErr("cannot read the files", err)
+
+		for _, fName := range files {
+			f, err := os.Open(fName) //nolint:gosec
+			exitOnErr("cannot read the files", err)
+
+			err = prog.streamReader(f, fName)
+			_ = f.Close()
+
+			exitOnErr(fmt.Sprintf("cannot read %q", fName), err)
+		}
+	case len(cmdLineSVs) > 0:
+		st := newStreamState()
+		loc := location.New("argument")
+
+		for i, s := range cmdLineSVs {
+			loc.Incr()
+			loc.SetContent(s)
+			prog.streamEntry(st, prog.mkRptPrt(loc, i))
+		}
+
+		prog.streamEnd(st)
+	default:
+		exitOnErr("cannot read the standard input",
+			prog.streamReader(os.Stdin, "standard input"))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// runCheck runs the checks on the input and returns the standard output
+// and the exit status. The checks are run on the whole list or on each
+// entry as it is read according to the stream flag.
+func runCheck(t *testing.T, prog *prog, input string, stream bool,
+) ([]byte, int) {
+	t.Helper()
+
+	fio, err := testhelper.NewStdioFromString(input)
+	if err != nil {
+		t.Fatal("unexpected error faking IO", err)
+	}
+
+	if stream {
+		prog.streamLists(nil)
+	} else {
+		for _, el := range prog.getEntryLists(nil) {
+			prog.checkList(el)
+		}
+	}
+
+	prog.finishReport()
+
+	stdout, _, err := fio.Done()
+	if err != nil {
+		t.Fatal("unexpected error retrieving stdout and stderr", err)
+	}
+
+	return stdout, prog.exitStatus
+}
+
+func TestStream(t *testing.T) {
+	const input = "v1.0.0\n" +
+		"v1.0.2\n" +
+		"bad\n" +
+		"v2.0.0-rc.1\n" +
+		"v2.0.0-rc.3\n" +
+		"v1.0.3\n" +
+		"v2.0.0\n" +
+		"v2.0.0\n" +
+		"v1.2.0\n"
+
+	testCases := []struct {
+		testhelper.ID
+		setup func(*prog)
+	}{
+		{
+			ID:    testhelper.MkID("check-seq, json"),
+			setup: func(prog *prog) { prog.format = fmtJSON },
+		},
+		{
+			ID: testhelper.MkID("check-seq-by major, json"),
+			setup: func(prog *prog) {
+				prog.format = fmtJSON
+				prog.seqBy = seqByMajor
+			},
+		},
+		{
+			ID: testhelper.MkID("pre-release seq, sarif"),
+			setup: func(prog *prog) {
+				prog.format = fmtSARIF
+				prog.seqBy = seqByMajor
+				prog.chkPreRelSeq = true
+			},
+		},
+		{
+			ID: testhelper.MkID("policy, json"),
+			setup: func(prog *prog) {
+				prog.format = fmtJSON
+				prog.policy.maxPreRels = 1
+				prog.policy.forbidV0 = true
+			},
+		},
+		{
+			ID: testhelper.MkID("fill-gaps, text"),
+			setup: func(prog *prog) {
+				prog.fillGaps = true
+				prog.seqBy = seqByMajor
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		batchProg := newProg()
+		batchProg.checkSeq = true
+		tc.setup(batchProg)
+
+		streamProg := newProg()
+		streamProg.checkSeq = true
+		tc.setup(streamProg)
+
+		batchOut, batchStatus := runCheck(t, batchProg, input, false)
+		streamOut, streamStatus := runCheck(t, streamProg, input, true)
+
+		testhelper.DiffInt(t, tc.IDStr(), "exit status",
+			streamStatus, batchStatus)
+
+		// the text format reports problems as they are found and so the
+		// order may differ; the other formats report by entry
+		if batchProg.format != fmtText {
+			testhelper.DiffString(t, tc.IDStr(), "report",
+				string(streamOut), string(batchOut))
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+// Max is the largest value
+const Max = 10
+
+// F does something; the parameter names have changed
+func F(x, y int) error { return nil }
+
+// T is a type
+type T struct {
+	Name  string
+	Count int
+	size  int
+}
+
+// Len returns the length
+func (t *T) Len() int { return t.size }
+
+// H is new
+func H() {}
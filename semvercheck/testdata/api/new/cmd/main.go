@@ -0,0 +1,6 @@
+package main
+
+// Exported is in a main package and so is not part of the API
+func Exported() {}
+
+func main() {}
@@ -0,0 +1,7 @@
+package sub
+
+// I is an interface; the methods have been reordered
+type I interface {
+	B(int)
+	A() string
+}
@@ -0,0 +1,19 @@
+package api
+
+// Max is the largest value
+const Max = 10
+
+// F does something
+func F(a, b int) error { return nil }
+
+// G will be removed
+func G() {}
+
+// T is a type
+type T struct {
+	Name string
+	size int
+}
+
+// Len returns the length
+func (t T) Len() int { return t.size }
@@ -0,0 +1,4 @@
+package internal
+
+// X is not part of the API
+func X() {}
@@ -0,0 +1,7 @@
+package sub
+
+// I is an interface
+type I interface {
+	A() string
+	B(int)
+}
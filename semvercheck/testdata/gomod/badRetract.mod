@@ -0,0 +1,3 @@
+module example.com/mod
+
+retract [v1.3.0, v1.2.0]
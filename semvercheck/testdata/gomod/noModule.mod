@@ -0,0 +1 @@
+go 1.26
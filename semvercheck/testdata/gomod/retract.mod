@@ -0,0 +1,10 @@
+module example.com/mod
+
+go 1.26
+
+retract v1.0.1 // published too early
+
+retract (
+	[v1.2.0, v1.3.9] // broken dependency
+	v1.5.0 // security problem
+)
@@ -0,0 +1,4 @@
+module example.com/mod
+
+require (
+	example.com/other v1.0.0
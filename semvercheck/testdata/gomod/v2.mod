@@ -0,0 +1,8 @@
+// a module with a major version suffix
+module "example.com/mod/v2" // the v2 module
+
+go 1.26
+
+require (
+	example.com/other v1.0.0 // indirect
+)
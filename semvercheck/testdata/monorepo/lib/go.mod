@@ -0,0 +1,5 @@
+module example.com/mono/lib/v2
+
+go 1.26
+
+retract v2.0.1 // broken
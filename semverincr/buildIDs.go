@@ -0,0 +1,229 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gitrepo"
+)
+
+// These are the names of the placeholders in a build ID template
+const (
+	tmplDate    = "date"
+	tmplTime    = "time"
+	tmplGitSHA  = "gitsha"
+	tmplCounter = "counter"
+	tmplEnv     = "env"
+)
+
+// These are the default arguments of the placeholders
+const (
+	dfltDateLayout = "20060102"
+	dfltTimeLayout = "150405"
+	dfltSHALen     = 7
+)
+
+// buildEnv provides the values used to expand a build ID template. The git
+// values are only found if the template needs them.
+type buildEnv struct {
+	now     time.Time
+	getenv  func(string) string
+	gitSHA  func() (string, error)
+	counter func() (int, error)
+}
+
+// sanitiseBuildID replaces any characters which are not allowed in a build
+// ID with a '-'
+func sanitiseBuildID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') || r == '-' {
+			return r
+		}
+
+		return '-'
+	}, s)
+}
+
+// expandPlaceholder returns the value of the placeholder
+func (be buildEnv) expandPlaceholder(name, arg string, hasArg bool) (string,
+	error,
+) {
+	switch name {
+	case tmplDate:
+		if !hasArg {
+			arg = dfltDateLayout
+		}
+
+		return be.now.Format(arg), nil
+	case tmplTime:
+		if !hasArg {
+			arg = dfltTimeLayout
+		}
+
+		return be.now.Format(arg), nil
+	case tmplGitSHA:
+		n := dfltSHALen
+
+		if hasArg {
+			var err error
+
+			n, err = strconv.Atoi(arg)
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("bad length for {%s}: %q", name, arg)
+			}
+		}
+
+		sha, err := be.gitSHA()
+		if err != nil {
+			return "", err
+		}
+
+		return sha[:min(n, len(sha))], nil
+	case tmplCounter:
+		if hasArg {
+			return "", fmt.Errorf("{%s} does not take an argument", name)
+		}
+
+		count, err := be.counter()
+		if err != nil {
+			return "", err
+		}
+
+		return strconv.Itoa(count), nil
+	case tmplEnv:
+		val := be.getenv(arg)
+		if val == "" {
+			return "", fmt.Errorf("the environment variable %q is not set",
+				arg)
+		}
+
+		return val, nil
+	}
+
+	return "", fmt.Errorf("unknown placeholder: {%s}", name)
+}
+
+// expandBuildIDs expands the template and returns the build IDs it gives.
+// The template is text with placeholders in braces; the placeholders are
+// replaced by their values, with any characters not allowed in a build ID
+// replaced by '-', and the result is split into IDs at each '.'.
+func (be buildEnv) expandBuildIDs(tmpl string) ([]string, error) {
+	var sb strings.Builder
+
+	for rest := tmpl; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			sb.WriteString(rest)
+			break
+		}
+
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("bad template: %q: unexpected '}'", tmpl)
+		}
+
+		sb.WriteString(rest[:open])
+		rest = rest[open+1:]
+
+		closing := strings.IndexAny(rest, "{}")
+		if closing < 0 || rest[closing] == '{' {
+			return nil, fmt.Errorf("bad template: %q: unclosed '{'", tmpl)
+		}
+
+		name, arg, hasArg := strings.Cut(rest[:closing], ":")
+
+		val, err := be.expandPlaceholder(name, arg, hasArg)
+		if err != nil {
+			return nil, fmt.Errorf("bad template: %q: %w", tmpl, err)
+		}
+
+		sb.WriteString(sanitiseBuildID(val))
+		rest = rest[closing+1:]
+	}
+
+	ids := strings.Split(sb.String(), ".")
+
+	if err := semver.CheckAllBuildIDs(ids); err != nil {
+		return nil, fmt.Errorf("the template %q gives bad build IDs: %q: %w",
+			tmpl, sb.String(), err)
+	}
+
+	return ids, nil
+}
+
+// gitHeadSHA returns the hash of the commit at HEAD of the repository
+func gitHeadSHA(dir string) (string, error) {
+	r, err := gitrepo.Open(dir)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hash, err := r.Resolve("HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return r.Peel(hash)
+}
+
+// gitCommitCount returns the number of commits made since the last tag.
+// These are the commits which have not been released, as found by
+// gitCommitMsgs, so any commits merged in from a branch which were already
+// released are not counted.
+func gitCommitCount(dir string) (int, error) {
+	msgs, err := gitCommitMsgs(dir)
+	return len(msgs), err
+}
+
+// newBuildEnv returns the buildEnv for the program. The git repository is
+// the one given by the git-repo parameter or, if that is not given, the
+// one in the current directory.
+func (prog *prog) newBuildEnv() buildEnv {
+	dir := prog.gitRepo
+	if dir == "" {
+		dir = "."
+	}
+
+	return buildEnv{
+		now:    time.Now().UTC(),
+		getenv: os.Getenv,
+		gitSHA: func() (string, error) {
+			sha, err := gitHeadSHA(dir)
+			if err != nil {
+				return "", fmt.Errorf("cannot find the git commit: %w", err)
+			}
+
+			return sha, nil
+		},
+		counter: func() (int, error) {
+			count, err := gitCommitCount(dir)
+			if err != nil {
+				return 0, fmt.Errorf("cannot count the git commits: %w", err)
+			}
+
+			return count, nil
+		},
+	}
+}
+
+// templateBuildIDs returns the build IDs from the template. They are
+// checked against any build ID checks.
+func (prog *prog) templateBuildIDs(be buildEnv) ([]string, error) {
+	bIDs, err := be.expandBuildIDs(prog.buildIDTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = semver.CheckRules(bIDs, prog.semverChecks.BuildIDChecks)
+	if err != nil {
+		return nil, errors.New("bad Build IDs: " + err.Error())
+	}
+
+	return bIDs, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nickwells/semvertools/internal/gittest"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+// testBuildEnv returns a buildEnv giving fixed values
+func testBuildEnv() buildEnv {
+	env := map[string]string{
+		"BUILD_NUMBER": "42",
+		"BRANCH":       "feature/x_y",
+	}
+
+	return buildEnv{
+		now:    time.Date(2024, time.March, 5, 6, 7, 8, 0, time.UTC),
+		getenv: func(name string) string { return env[name] },
+		gitSHA: func() (string, error) {
+			return "0123456789abcdef0123456789abcdef01234567", nil
+		},
+		counter: func() (int, error) { return 3, nil },
+	}
+}
+
+func TestExpandBuildIDs(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		tmpl   string
+		expIDs []string
+	}{
+		{
+			ID:     testhelper.MkID("literal"),
+			tmpl:   "build.7",
+			expIDs: []string{"build", "7"},
+		},
+		{
+			ID:     testhelper.MkID("all placeholders"),
+			tmpl:   "{date:20060102}.{time}.{gitsha:7}.{counter}.{env:BUILD_NUMBER}",
+			expIDs: []string{"20240305", "060708", "0123456", "3", "42"},
+		},
+		{
+			ID:     testhelper.MkID("defaults"),
+			tmpl:   "b{date}-{gitsha}",
+			expIDs: []string{"b20240305-0123456"},
+		},
+		{
+			ID:     testhelper.MkID("sanitised"),
+			tmpl:   "{env:BRANCH}.{date:2006-01-02T15:04}",
+			expIDs: []string{"feature-x-y", "2024-03-05T06-07"},
+		},
+		{
+			ID:     testhelper.MkID("long gitsha"),
+			tmpl:   "{gitsha:99}",
+			expIDs: []string{"0123456789abcdef0123456789abcdef01234567"},
+		},
+		{
+			ID:     testhelper.MkID("unknown placeholder"),
+			tmpl:   "{nonesuch}",
+			ExpErr: testhelper.MkExpErr("unknown placeholder: {nonesuch}"),
+		},
+		{
+			ID:     testhelper.MkID("unclosed"),
+			tmpl:   "{date.{time}",
+			ExpErr: testhelper.MkExpErr("unclosed '{'"),
+		},
+		{
+			ID:     testhelper.MkID("unexpected close"),
+			tmpl:   "date}",
+			ExpErr: testhelper.MkExpErr("unexpected '}'"),
+		},
+		{
+			ID:     testhelper.MkID("bad gitsha length"),
+			tmpl:   "{gitsha:x}",
+			ExpErr: testhelper.MkExpErr(`bad length for {gitsha}: "x"`),
+		},
+		{
+			ID:     testhelper.MkID("counter with argument"),
+			tmpl:   "{counter:2}",
+			ExpErr: testhelper.MkExpErr("{counter} does not take an argument"),
+		},
+		{
+			ID:   testhelper.MkID("unset variable"),
+			tmpl: "{env:NONESUCH}",
+			ExpErr: testhelper.MkExpErr(
+				`the environment variable "NONESUCH" is not set`),
+		},
+		{
+			ID:     testhelper.MkID("empty build ID"),
+			tmpl:   "{counter}..x",
+			ExpErr: testhelper.MkExpErr(`gives bad build IDs: "3..x"`),
+		},
+	}
+
+	be := testBuildEnv()
+
+	for _, tc := range testCases {
+		ids, err := be.expandBuildIDs(tc.tmpl)
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "build IDs", ids, tc.expIDs)
+	}
+}
+
+func TestExpandBuildIDsGitErr(t *testing.T) {
+	be := testBuildEnv()
+	be.gitSHA = func() (string, error) { return "", errors.New("no repo") }
+
+	_, err := be.expandBuildIDs("{date}")
+	if err != nil {
+		t.Error("the git sha should not be needed:", err)
+	}
+
+	_, err = be.expandBuildIDs("{gitsha}")
+	if err == nil || !strings.Contains(err.Error(), "no repo") {
+		t.Error("the git error should be reported, got:", err)
+	}
+}
+
+func TestGitHeadSHA(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := t.TempDir()
+	gittest.Run(t, dir, nil, "init", "-q", "-b", "main")
+
+	commitFile(t, dir, "feat: the first release")
+	gittest.Run(t, dir, nil, "tag", "v1.0.0")
+	commitFile(t, dir, "fix: mend a")
+	commitFile(t, dir, "docs: explain b")
+
+	sha, err := gitHeadSHA(dir)
+	if err != nil {
+		t.Fatal("unexpected error finding the HEAD commit:", err)
+	}
+
+	testhelper.DiffString(t, "git", "sha",
+		sha, strings.TrimSpace(gittest.Run(t, dir, nil, "rev-parse", "HEAD")))
+
+	count, err := gitCommitCount(dir)
+	if err != nil {
+		t.Fatal("unexpected error counting the commits:", err)
+	}
+
+	testhelper.DiffInt(t, "git", "count", count, 2)
+}
+
+func TestGitCommitCountMerged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := mkMergedRepo(t)
+
+	count, err := gitCommitCount(dir)
+	if err != nil {
+		t.Fatal("unexpected error counting the commits:", err)
+	}
+
+	testhelper.DiffInt(t, "merged", "count", count, 3)
+
+	be := buildEnv{
+		counter: func() (int, error) { return gitCommitCount(dir) },
+	}
+
+	ids, err := be.expandBuildIDs("c{counter}")
+	if err != nil {
+		t.Fatal("unexpected error expanding the template:", err)
+	}
+
+	testhelper.DiffStringSlice(t, "merged", "build IDs", ids, []string{"c3"})
+}
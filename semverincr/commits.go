@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gitrepo"
+)
+
+// commitMsg records a commit message and where it came from
+type commitMsg struct {
+	from string
+	text string
+}
+
+// subject returns the first line of the commit message
+func (cm commitMsg) subject() string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(cm.text), "\n")
+	return subject
+}
+
+// ccHeaderRE matches the header of a conventional commit message, such as
+//
+//	feat(parser)!: allow a trailing comma
+//
+// The sub-matches are the type and the breaking change marker
+var ccHeaderRE = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?: \S`)
+
+// ccBreakingRE matches a footer which marks a breaking change
+var ccBreakingRE = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// ccPart returns the part of the semver which the commit message requires
+// to be incremented. This is incrMajor for a breaking change, incrMinor
+// for a new feature, incrPatch for a fix or a performance improvement and
+// incrNone for anything else.
+func ccPart(msg string) string {
+	msg = strings.TrimSpace(msg)
+
+	if ccBreakingRE.MatchString(msg) {
+		return incrMajor
+	}
+
+	m := ccHeaderRE.FindStringSubmatch(msg)
+	if m == nil {
+		return incrNone
+	}
+
+	if m[2] == "!" {
+		return incrMajor
+	}
+
+	switch strings.ToLower(m[1]) {
+	case "feat":
+		return incrMinor
+	case "fix", "perf":
+		return incrPatch
+	}
+
+	return incrNone
+}
+
+// partRank gives the order of the parts chosen from the commit messages
+var partRank = map[string]int{
+	incrNone:  0,
+	incrPatch: 1,
+	incrMinor: 2,
+	incrMajor: 3,
+}
+
+// autoPart returns the part of the semver to be incremented given the
+// commit messages, together with the reason for the choice. The largest
+// part required by any of the commit messages is chosen. While the major
+// version is 0 a breaking change only requires the minor version to be
+// incremented.
+func autoPart(sv *semver.SV, msgs []commitMsg) (string, string) {
+	part := incrNone
+
+	var cause commitMsg
+
+	for _, cm := range msgs {
+		p := ccPart(cm.text)
+		if partRank[p] > partRank[part] {
+			part, cause = p, cm
+		}
+	}
+
+	if part == incrNone {
+		return incrNone, fmt.Sprintf(
+			"none of the %d commit messages needs a new version", len(msgs))
+	}
+
+	reason := fmt.Sprintf("%s: %s", cause.from, cause.subject())
+
+	if part == incrMajor && sv.Major() == 0 {
+		return incrMinor, reason +
+			" (a breaking change only needs a new minor version under v0)"
+	}
+
+	return part, reason
+}
+
+// readCommitMsgs reads the commit messages. If the text holds any NUL
+// characters, as given by 'git log -z', the messages are separated by
+// them; otherwise each non-blank line is taken to be a separate message.
+func readCommitMsgs(r io.Reader, name string) ([]commitMsg, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []commitMsg{}
+
+	if bytes.IndexByte(content, 0) >= 0 {
+		for i, text := range strings.Split(string(content), "\x00") {
+			if strings.TrimSpace(text) != "" {
+				msgs = append(msgs, commitMsg{
+					from: fmt.Sprintf("%s: message %d", name, i+1),
+					text: text,
+				})
+			}
+		}
+
+		return msgs, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+
+		if text := scanner.Text(); strings.TrimSpace(text) != "" {
+			msgs = append(msgs, commitMsg{
+				from: fmt.Sprintf("%s:%d", name, lineNum),
+				text: text,
+			})
+		}
+	}
+
+	return msgs, scanner.Err()
+}
+
+// shortHashLen is the length of the abbreviated commit hash used when
+// reporting a commit
+const shortHashLen = 7
+
+// walkCommits visits the commits reachable from the starting commits,
+// breadth first, calling f for each one. Commits already in seen are not
+// visited (nor are their parents) and each commit visited is added to
+// seen.
+func walkCommits(r *gitrepo.Repo, start []string, seen map[string]bool,
+	f func(hash string, c *gitrepo.Commit),
+) error {
+	for todo := start; len(todo) > 0; todo = todo[1:] {
+		hash := todo[0]
+		if seen[hash] {
+			continue
+		}
+
+		seen[hash] = true
+
+		c, err := r.ReadCommit(hash)
+		if err != nil {
+			return err
+		}
+
+		f(hash, c)
+		todo = append(todo, c.Parents...)
+	}
+
+	return nil
+}
+
+// releasedCommits returns the set of commits reachable from any commit
+// having a tag which is a semver. These are the commits which have
+// already been released.
+func releasedCommits(r *gitrepo.Repo) (map[string]bool, error) {
+	tags, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := []string{}
+
+	for _, tag := range tags {
+		if _, err := semver.ParseSV(tag.ShortName()); err != nil {
+			continue
+		}
+
+		hash, err := r.Peel(tag.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tag.Name, err)
+		}
+
+		tagged = append(tagged, hash)
+	}
+
+	released := map[string]bool{}
+
+	err = walkCommits(r, tagged, released, func(string, *gitrepo.Commit) {})
+
+	return released, err
+}
+
+// gitCommitMsgs returns the messages of the commits in the repository made
+// since the last tag. These are the commits reachable from HEAD but not
+// from any commit having a tag which is a semver. Note that a commit
+// merged in from a branch which forked before the last tag may still have
+// been released and so the released commits are all found first.
+func gitCommitMsgs(dir string) ([]commitMsg, error) {
+	r, err := gitrepo.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	released, err := releasedCommits(r)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := r.Resolve("HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []commitMsg{}
+
+	err = walkCommits(r, []string{head}, released,
+		func(hash string, c *gitrepo.Commit) {
+			msgs = append(msgs, commitMsg{
+				from: "commit " + hash[:shortHashLen],
+				text: c.Message,
+			})
+		})
+
+	return msgs, err
+}
+
+// getCommitMsgs reads the commit messages from the chosen source
+func (prog *prog) getCommitMsgs() ([]commitMsg, error) {
+	switch {
+	case prog.gitRepo != "":
+		return gitCommitMsgs(prog.gitRepo)
+	case prog.commitsFile != "":
+		f, err := os.Open(prog.commitsFile) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return readCommitMsgs(f, prog.commitsFile)
+	}
+
+	return readCommitMsgs(os.Stdin, "standard input")
+}
+
+// chooseAutoPart reads the commit messages and sets the part of the semver
+// to be incremented accordingly
+func (prog *prog) chooseAutoPart() error {
+	msgs, err := prog.getCommitMsgs()
+	if err != nil {
+		return fmt.Errorf("cannot read the commit messages: %w", err)
+	}
+
+	prog.incrPart, prog.autoReason = autoPart(&prog.semverVals.SemVer, msgs)
+
+	if prog.showReason {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", prog.incrPart, prog.autoReason)
+	}
+
+	return nil
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gittest"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestCCPart(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		msg     string
+		expPart string
+	}{
+		{ID: testhelper.MkID("feat"), msg: "feat: add X", expPart: incrMinor},
+		{
+			ID:      testhelper.MkID("feat with scope"),
+			msg:     "feat(parser): add X",
+			expPart: incrMinor,
+		},
+		{ID: testhelper.MkID("fix"), msg: "fix: mend X", expPart: incrPatch},
+		{ID: testhelper.MkID("perf"), msg: "perf: speed X", expPart: incrPatch},
+		{ID: testhelper.MkID("docs"), msg: "docs: tidy X", expPart: incrNone},
+		{ID: testhelper.MkID("bang"), msg: "refactor!: drop X", expPart: incrMajor},
+		{
+			ID:      testhelper.MkID("bang with scope"),
+			msg:     "feat(api)!: change X",
+			expPart: incrMajor,
+		},
+		{
+			ID:      testhelper.MkID("footer"),
+			msg:     "fix: mend X\n\nBREAKING CHANGE: X now differs",
+			expPart: incrMajor,
+		},
+		{
+			ID:      testhelper.MkID("footer with hyphen"),
+			msg:     "fix: mend X\n\nBREAKING-CHANGE: X now differs",
+			expPart: incrMajor,
+		},
+		{
+			ID:      testhelper.MkID("not conventional"),
+			msg:     "Add a feat: of engineering",
+			expPart: incrNone,
+		},
+		{ID: testhelper.MkID("no description"), msg: "feat: ", expPart: incrNone},
+	}
+
+	for _, tc := range testCases {
+		testhelper.DiffString(t, tc.IDStr(), "part", ccPart(tc.msg), tc.expPart)
+	}
+}
+
+func TestAutoPart(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		sv        string
+		msgs      []string
+		expPart   string
+		expReason string
+	}{
+		{
+			ID:        testhelper.MkID("no commits"),
+			sv:        "v1.2.3",
+			expPart:   incrNone,
+			expReason: "none of the 0 commit messages needs a new version",
+		},
+		{
+			ID:        testhelper.MkID("largest wins"),
+			sv:        "v1.2.3",
+			msgs:      []string{"fix: a", "feat: b", "fix: c", "feat: d"},
+			expPart:   incrMinor,
+			expReason: "msg 2: feat: b",
+		},
+		{
+			ID:        testhelper.MkID("breaking"),
+			sv:        "v1.2.3",
+			msgs:      []string{"fix: a", "feat!: b"},
+			expPart:   incrMajor,
+			expReason: "msg 2: feat!: b",
+		},
+		{
+			ID:      testhelper.MkID("breaking under v0"),
+			sv:      "v0.2.3",
+			msgs:    []string{"fix: a\n\nBREAKING CHANGE: a differs"},
+			expPart: incrMinor,
+			expReason: "msg 1: fix: a" +
+				" (a breaking change only needs a new minor version under v0)",
+		},
+		{
+			ID:        testhelper.MkID("feat under v0"),
+			sv:        "v0.2.3",
+			msgs:      []string{"feat: a"},
+			expPart:   incrMinor,
+			expReason: "msg 1: feat: a",
+		},
+	}
+
+	for _, tc := range testCases {
+		sv, err := semver.ParseSV(tc.sv)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot parse the semver:", err)
+		}
+
+		msgs := []commitMsg{}
+		for i, text := range tc.msgs {
+			msgs = append(msgs, commitMsg{
+				from: fmt.Sprintf("msg %d", i+1),
+				text: text,
+			})
+		}
+
+		part, reason := autoPart(sv, msgs)
+		testhelper.DiffString(t, tc.IDStr(), "part", part, tc.expPart)
+		testhelper.DiffString(t, tc.IDStr(), "reason", reason, tc.expReason)
+	}
+}
+
+func TestReadCommitMsgs(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		input    string
+		expFrom  []string
+		expParts []string
+	}{
+		{
+			ID:       testhelper.MkID("lines"),
+			input:    "fix: a\n\nfeat: b\n",
+			expFrom:  []string{"in:1", "in:3"},
+			expParts: []string{incrPatch, incrMinor},
+		},
+		{
+			ID: testhelper.MkID("NUL separated"),
+			input: "fix: a\n\nBREAKING CHANGE: a differs\n\x00" +
+				"feat: b\n\nsome detail\n\x00",
+			expFrom:  []string{"in: message 1", "in: message 2"},
+			expParts: []string{incrMajor, incrMinor},
+		},
+	}
+
+	for _, tc := range testCases {
+		msgs, err := readCommitMsgs(strings.NewReader(tc.input), "in")
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": unexpected error:", err)
+		}
+
+		from := []string{}
+		parts := []string{}
+
+		for _, cm := range msgs {
+			from = append(from, cm.from)
+			parts = append(parts, ccPart(cm.text))
+		}
+
+		testhelper.DiffStringSlice(t, tc.IDStr(), "from", from, tc.expFrom)
+		testhelper.DiffStringSlice(t, tc.IDStr(), "parts", parts, tc.expParts)
+	}
+}
+
+// commitFile changes the file in the repository and commits the change
+// with the message
+func commitFile(t *testing.T, dir, msg string) {
+	t.Helper()
+
+	fName := filepath.Join(dir, "file.txt")
+
+	f, err := os.OpenFile(fName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal("cannot open", fName, ":", err)
+	}
+
+	_, err = f.WriteString(msg + "\n")
+	_ = f.Close()
+
+	if err != nil {
+		t.Fatal("cannot write", fName, ":", err)
+	}
+
+	gittest.Run(t, dir, nil, "add", "file.txt")
+	gittest.Run(t, dir, nil, "commit", "-q", "-m", msg)
+}
+
+func TestGitCommitMsgs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := t.TempDir()
+	gittest.Run(t, dir, nil, "init", "-q", "-b", "main")
+
+	commitFile(t, dir, "feat!: the first release")
+	gittest.Run(t, dir, nil, "tag", "v1.0.0")
+	commitFile(t, dir, "feat: released")
+	gittest.Run(t, dir, nil, "tag", "-a", "-m", "annotated", "v1.1.0")
+	commitFile(t, dir, "fix: mend a")
+	gittest.Run(t, dir, nil, "tag", "not-a-semver")
+	commitFile(t, dir, "docs: explain b")
+
+	msgs, err := gitCommitMsgs(dir)
+	if err != nil {
+		t.Fatal("unexpected error reading the commits:", err)
+	}
+
+	subjects := []string{}
+	for _, cm := range msgs {
+		subjects = append(subjects, cm.subject())
+	}
+
+	testhelper.DiffStringSlice(t, "git", "subjects",
+		subjects, []string{"docs: explain b", "fix: mend a"})
+
+	part, _ := autoPart(semver.NewSVOrPanic(1, 1, 0, nil, nil), msgs)
+	testhelper.DiffString(t, "git", "part", part, incrPatch)
+}
+
+// mkMergedRepo creates a git repository in which a side branch which
+// forked before the last tag is merged in after it. The history is:
+//
+//	feat!: the first release - feat!: break [v2.0.0] - docs: after the tag
+//	  \                                                   \
+//	   fix: on the side ----------------------------------- chore: merge side
+//
+// The first commit was released in v2.0.0 (though it has no tag of its
+// own) and so only the commits after the tag and on the side branch are
+// new.
+func mkMergedRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	gittest.Run(t, dir, nil, "init", "-q", "-b", "main")
+
+	commitFile(t, dir, "feat!: the first release")
+	gittest.Run(t, dir, nil, "branch", "side")
+	commitFile(t, dir, "feat!: break")
+	gittest.Run(t, dir, nil, "tag", "v2.0.0")
+	commitFile(t, dir, "docs: after the tag")
+
+	gittest.Run(t, dir, nil, "checkout", "-q", "side")
+	sideFile := filepath.Join(dir, "side.txt")
+
+	if err := os.WriteFile(sideFile, []byte("side\n"), 0o600); err != nil {
+		t.Fatal("cannot write", sideFile, ":", err)
+	}
+
+	gittest.Run(t, dir, nil, "add", "side.txt")
+	gittest.Run(t, dir, nil, "commit", "-q", "-m", "fix: on the side")
+
+	gittest.Run(t, dir, nil, "checkout", "-q", "main")
+	gittest.Run(t, dir, nil, "merge", "-q", "--no-ff", "-m", "chore: merge side", "side")
+
+	return dir
+}
+
+func TestGitCommitMsgsMerged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := mkMergedRepo(t)
+
+	msgs, err := gitCommitMsgs(dir)
+	if err != nil {
+		t.Fatal("unexpected error reading the commits:", err)
+	}
+
+	subjects := []string{}
+	for _, cm := range msgs {
+		subjects = append(subjects, cm.subject())
+	}
+
+	testhelper.DiffStringSlice(t, "merged", "subjects",
+		subjects, []string{
+			"chore: merge side", "docs: after the tag", "fix: on the side",
+		})
+
+	part, _ := autoPart(semver.NewSVOrPanic(2, 0, 0, nil, nil), msgs)
+	testhelper.DiffString(t, "merged", "part", part, incrPatch)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gitrepo"
+)
+
+// These are the sources from which the existing versions can be read
+const (
+	existingNone  = "none"
+	existingStdin = "stdin"
+	existingGit   = "git"
+)
+
+// releaseKey returns the key of the release of the semver; that is, the
+// key of the semver without any pre-release IDs
+func releaseKey(sv *semver.SV) string {
+	return fmt.Sprintf("v%d.%d.%d", sv.Major(), sv.Minor(), sv.Patch())
+}
+
+// vsnKey returns a key for the semver which is the same for all semvers
+// having the same precedence. Build IDs do not affect the precedence and
+// so v1.2.3+a and v1.2.3+b have the same key.
+func vsnKey(sv *semver.SV) string {
+	key := releaseKey(sv)
+	if sv.HasPreRelIDs() {
+		key += "-" + strings.Join(sv.PreRelIDs(), ".")
+	}
+
+	return key
+}
+
+// readExistingVsns reads the existing versions, one per line. Blank lines
+// and lines starting with '#' are ignored. A version may be given with or
+// without a leading 'v'.
+func readExistingVsns(r io.Reader, name string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sv, err := semver.ParseStrictSV(strings.TrimPrefix(line, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNum, err)
+		}
+
+		existing[vsnKey(sv)] = true
+	}
+
+	return existing, scanner.Err()
+}
+
+// gitExistingVsns returns the versions given by the tags in the
+// repository. Any tags which are not semvers are ignored.
+func gitExistingVsns(dir string) (map[string]bool, error) {
+	r, err := gitrepo.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	tags, err := r.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := map[string]bool{}
+
+	for _, tag := range tags {
+		sv, err := semver.ParseSV(tag.ShortName())
+		if err != nil {
+			continue
+		}
+
+		existing[vsnKey(sv)] = true
+	}
+
+	return existing, nil
+}
+
+// avoidExisting changes the semver until it is not one of the existing
+// versions. Each time it is found it is incremented as for incrLeast so
+// the PRID is incremented if the semver has one, otherwise the patch
+// version is incremented. So, for instance, if 'rc.1' to 'rc.3' exist then
+// 'rc.1' is changed to 'rc.4'. It is an error if the semver is a
+// pre-release of a version which has already been released as the
+// pre-release would come before the release. It is also an error if the
+// semver is the release asked for (the release flag is set) and it has
+// already been released.
+func avoidExisting(sv *semver.SV, existing map[string]bool, release bool,
+) error {
+	relKey := releaseKey(sv)
+
+	if sv.HasPreRelIDs() {
+		if existing[relKey] {
+			return fmt.Errorf("%s has already been released so the"+
+				" pre-release %s would come before it",
+				relKey, vsnKey(sv))
+		}
+	} else if release && existing[relKey] {
+		return fmt.Errorf("%s has already been released", relKey)
+	}
+
+	for existing[vsnKey(sv)] {
+		if !sv.HasPreRelIDs() {
+			sv.IncrPatch()
+			continue
+		}
+
+		if err := incrLastPartOfPRID(sv); err != nil {
+			return fmt.Errorf("%s already exists and cannot be changed: %w",
+				vsnKey(sv), err)
+		}
+	}
+
+	return nil
+}
+
+// getExistingVsns reads the existing versions from the chosen source
+func (prog *prog) getExistingVsns() (map[string]bool, error) {
+	switch {
+	case prog.existingFile != "":
+		f, err := os.Open(prog.existingFile) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return readExistingVsns(f, prog.existingFile)
+	case prog.existingFrom == existingGit:
+		dir := prog.gitRepo
+		if dir == "" {
+			dir = "."
+		}
+
+		return gitExistingVsns(dir)
+	}
+
+	return readExistingVsns(os.Stdin, "standard input")
+}
+
+// avoidExistingVsns reads the existing versions and changes the semver
+// until it is not one of them
+func (prog *prog) avoidExistingVsns() error {
+	existing, err := prog.getExistingVsns()
+	if err != nil {
+		return fmt.Errorf("cannot read the existing versions: %w", err)
+	}
+
+	return avoidExisting(&prog.semverVals.SemVer, existing, prog.release)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/semvertools/internal/gittest"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestReadExistingVsns(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		input  string
+		expLen int
+	}{
+		{
+			ID:     testhelper.MkID("good"),
+			input:  "v1.2.0-rc.1\n1.2.0-rc.2+b\n# comment\n\nv1.2.0\n",
+			expLen: 3,
+		},
+		{
+			ID:     testhelper.MkID("same precedence"),
+			input:  "v1.2.0+a\nv1.2.0+b\n",
+			expLen: 1,
+		},
+		{
+			ID:     testhelper.MkID("bad"),
+			input:  "v1.2.0\nnot-a-version\n",
+			ExpErr: testhelper.MkExpErr("in:2: bad semantic version ID"),
+		},
+	}
+
+	for _, tc := range testCases {
+		existing, err := readExistingVsns(strings.NewReader(tc.input), "in")
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		testhelper.DiffInt(t, tc.IDStr(), "count", len(existing), tc.expLen)
+	}
+}
+
+func TestAvoidExisting(t *testing.T) {
+	existing := map[string]bool{
+		"v1.2.0-rc.1": true,
+		"v1.2.0":      true,
+		"v1.2.1":      true,
+		"v1.3.0-rc.1": true,
+		"v1.3.0-rc.2": true,
+		"v1.3.0-rc.3": true,
+		"v1.3.0-beta": true,
+	}
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		sv         *semver.SV
+		release    bool
+		svExpected *semver.SV
+	}{
+		{
+			ID:         testhelper.MkID("next free rc"),
+			sv:         semver.NewSVOrPanic(1, 3, 0, []string{"rc", "1"}, nil),
+			svExpected: semver.NewSVOrPanic(1, 3, 0, []string{"rc", "4"}, nil),
+		},
+		{
+			ID:         testhelper.MkID("rc of a released version"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, []string{"rc", "2"}, nil),
+			svExpected: semver.NewSVOrPanic(1, 2, 0, []string{"rc", "2"}, nil),
+			ExpErr: testhelper.MkExpErr("v1.2.0 has already been released" +
+				" so the pre-release v1.2.0-rc.2 would come before it"),
+		},
+		{
+			ID:         testhelper.MkID("next free patch"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, nil, []string{"b"}),
+			svExpected: semver.NewSVOrPanic(1, 2, 2, nil, []string{"b"}),
+		},
+		{
+			ID:         testhelper.MkID("release already released"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, nil, nil),
+			release:    true,
+			svExpected: semver.NewSVOrPanic(1, 2, 0, nil, nil),
+			ExpErr:     testhelper.MkExpErr("v1.2.0 has already been released"),
+		},
+		{
+			ID:         testhelper.MkID("release not released"),
+			sv:         semver.NewSVOrPanic(1, 3, 0, nil, nil),
+			release:    true,
+			svExpected: semver.NewSVOrPanic(1, 3, 0, nil, nil),
+		},
+		{
+			ID:         testhelper.MkID("not existing"),
+			sv:         semver.NewSVOrPanic(1, 4, 0, nil, nil),
+			svExpected: semver.NewSVOrPanic(1, 4, 0, nil, nil),
+		},
+		{
+			ID:         testhelper.MkID("PRID not numeric"),
+			sv:         semver.NewSVOrPanic(1, 3, 0, []string{"beta"}, nil),
+			svExpected: semver.NewSVOrPanic(1, 3, 0, []string{"beta"}, nil),
+			ExpErr: testhelper.MkExpErr(
+				"v1.3.0-beta already exists and cannot be changed"),
+		},
+	}
+
+	for _, tc := range testCases {
+		err := avoidExisting(tc.sv, existing, tc.release)
+		testhelper.CheckExpErr(t, err, tc)
+
+		if !semver.Equals(tc.sv, tc.svExpected) {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %s", tc.svExpected)
+			t.Logf("\t:      got: %s", tc.sv)
+			t.Errorf("\t: unexpected version\n")
+		}
+	}
+}
+
+func TestGitExistingVsns(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("the git command is not available")
+	}
+
+	dir := t.TempDir()
+	gittest.Run(t, dir, nil, "init", "-q", "-b", "main")
+
+	commitFile(t, dir, "feat: the first release")
+	gittest.Run(t, dir, nil, "tag", "-a", "-m", "annotated", "v1.0.0")
+	gittest.Run(t, dir, nil, "tag", "v1.1.0-rc.1")
+	gittest.Run(t, dir, nil, "tag", "not-a-semver")
+
+	existing, err := gitExistingVsns(dir)
+	if err != nil {
+		t.Fatal("unexpected error reading the tags:", err)
+	}
+
+	sv := semver.NewSVOrPanic(1, 1, 0, []string{"rc", "1"}, nil)
+
+	err = avoidExisting(sv, existing, false)
+	if err != nil {
+		t.Fatal("unexpected error avoiding the tags:", err)
+	}
+
+	testhelper.DiffString(t, "git", "version", sv.String(), "v1.1.0-rc.2")
+}
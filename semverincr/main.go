@@ -7,11 +7,14 @@ import (
 	"regexp"
 	"strconv"
 
+	"github.com/nickwells/check.mod/v2/check"
+	"github.com/nickwells/filecheck.mod/filecheck"
 	"github.com/nickwells/param.mod/v7/paction"
 	"github.com/nickwells/param.mod/v7/param"
 	"github.com/nickwells/param.mod/v7/psetter"
 	"github.com/nickwells/semver.mod/v3/semver"
 	"github.com/nickwells/semverparams.mod/v6/semverparams"
+	"github.com/nickwells/semvertools/internal/prid"
 )
 
 // Created: Wed Dec 26 11:19:14 2018
@@ -23,6 +26,9 @@ const (
 	incrPRID  = "prid"
 	incrLeast = "least"
 	incrNone  = "none"
+	incrAuto  = "auto"
+
+	incrNextStage = "next-stage"
 
 	clearAll   = "all"
 	clearNone  = "none"
@@ -32,8 +38,21 @@ const (
 	paramNameReleaseCandidate = "release-candidate"
 	paramNameRelease          = "release"
 	paramNameDfltPRID         = "default-pre-rel-IDs"
+	paramNameGitRepo          = "git-repo"
+	paramNameCommitsFile      = "commits-file"
+	paramNameShowReason       = "show-reason"
+	paramNameFile             = "file"
+	paramNameFileType         = "file-type"
+	paramNameBuildIDTemplate  = "build-id-template"
+	paramNamePreRelLadder     = "pre-rel-ladder"
+	paramNameExisting         = "existing"
+	paramNameExistingFile     = "existing-file"
 )
 
+// preRelStageRE matches a pre-release stage. A stage cannot hold any
+// digits as the stage of a pre-release ends at the first digit.
+var preRelStageRE = regexp.MustCompile(`^[a-zA-Z-]+$`)
+
 // prog holds the parameter values and intermediate results
 type prog struct {
 	dfltFirstPreRelIDs []string
@@ -43,6 +62,22 @@ type prog struct {
 	clearIDs string
 	incrPart string
 
+	preRelLadder []string
+
+	gitRepo     string
+	commitsFile string
+	showReason  bool
+	autoReason  string
+
+	fileName string
+	fileType string
+	vsnFile  *versionFile
+
+	buildIDTemplate string
+
+	existingFrom string
+	existingFile string
+
 	incrParamCounter  paction.Counter
 	setIDParamCounter paction.Counter
 
@@ -54,10 +89,13 @@ type prog struct {
 func newProg() *prog {
 	return &prog{
 		dfltFirstPreRelIDs: []string{"rc", "1"},
+		preRelLadder:       []string{"alpha", "beta", "rc"},
+
+		clearIDs: clearNone,
+		incrPart: incrLeast,
+		fileType: fileTypeAuto,
 
-		clearIDs:   clearNone,
-		incrPart:   incrLeast,
-		semverVals: semverparams.SemverVals{SemverAttrs: param.MustBeSet},
+		existingFrom: existingNone,
 	}
 }
 
@@ -69,6 +107,20 @@ func main() {
 
 	sv := &prog.semverVals.SemVer
 
+	if prog.fileName != "" {
+		err := prog.readVersionFile()
+		if err != nil {
+			reportProblem(sv, err.Error())
+		}
+	}
+
+	if prog.incrPart == incrAuto {
+		err := prog.chooseAutoPart()
+		if err != nil {
+			reportProblem(sv, err.Error())
+		}
+	}
+
 	err := prog.incr()
 	if err != nil {
 		reportProblem(sv, err.Error())
@@ -79,9 +131,48 @@ func main() {
 		reportProblem(sv, err.Error())
 	}
 
+	if prog.existingFrom != existingNone || prog.existingFile != "" {
+		err = prog.avoidExistingVsns()
+		if err != nil {
+			reportProblem(sv, err.Error())
+		}
+	}
+
+	if prog.vsnFile != nil {
+		err = prog.vsnFile.write(sv)
+		if err != nil {
+			reportProblem(sv, err.Error())
+		}
+	}
+
 	fmt.Println(sv)
 }
 
+// readVersionFile reads the semver from the file. The pre-release and
+// build IDs are checked as they would be if the semver had been given as a
+// parameter.
+func (prog *prog) readVersionFile() error {
+	vf, sv, err := readVersionFile(prog.fileName, prog.fileType)
+	if err != nil {
+		return err
+	}
+
+	err = semver.CheckRules(sv.PreRelIDs(), prog.semverChecks.PreRelIDChecks)
+	if err != nil {
+		return fmt.Errorf("%s: bad Pre-Release IDs: %w", prog.fileName, err)
+	}
+
+	err = semver.CheckRules(sv.BuildIDs(), prog.semverChecks.BuildIDChecks)
+	if err != nil {
+		return fmt.Errorf("%s: bad Build IDs: %w", prog.fileName, err)
+	}
+
+	prog.vsnFile = vf
+	prog.semverVals.SemVer = *sv
+
+	return nil
+}
+
 // reportProblem reports the semver and the message and exits
 func reportProblem(sv *semver.SV, msg string) {
 	fmt.Fprintln(os.Stderr, sv)
@@ -120,7 +211,22 @@ func (prog *prog) incr() error {
 		}
 
 		sv.IncrPatch()
+	case incrNextStage:
+		prIDs, err := prid.NextStage(sv.PreRelIDs(), prog.preRelLadder)
+		if err != nil {
+			return err
+		}
+
+		if len(prIDs) == 0 {
+			sv.ClearPreRelIDs()
+			return nil
+		}
+
+		return sv.SetPreRelIDs(prIDs)
 	case incrNone:
+	case incrAuto:
+		return errors.New("the part to increment has not been chosen" +
+			" from the commit messages")
 	default:
 		return fmt.Errorf("unknown increment choice: %q", prog.incrPart)
 	}
@@ -132,76 +238,14 @@ func (prog *prog) incr() error {
 // (which should have been checked to ensure it's non-empty) and will
 // increment any numeric part
 func incrLastPartOfPRID(sv *semver.SV) error {
-	prIDs := sv.PreRelIDs()
-	lastIdx := len(prIDs) - 1
-
-	newVal, err := incrNumInStr(prIDs[lastIdx])
+	prIDs, err := prid.IncrLast(sv.PreRelIDs())
 	if err != nil {
 		return err
 	}
 
-	prIDs[lastIdx] = newVal
-
 	return sv.SetPreRelIDs(prIDs)
 }
 
-// incrNumInStr will find the numeric part of the pre-release ID and
-// increment it, replacing it in the string in the same place as it was
-// found. If it is a wholly numeric string then it will be taken as a number
-// and incremented as normal, if it is embedded in a string just that part
-// will be incremented. For instance '123' will be changed to '124' but
-// 'RC012' will be changed to 'RC013'.
-func incrNumInStr(s string) (string, error) {
-	const (
-		wholeMatch = iota
-		prefixIdx
-		numIdx
-		suffixIdx
-		expectedLen
-	)
-
-	findNumPartRE := regexp.MustCompile("([^0-9]*)([0-9]+)(.*)")
-	parts := findNumPartRE.FindStringSubmatch(s)
-
-	if parts == nil {
-		return s, fmt.Errorf("the string (%q) has no numerical part", s)
-	}
-
-	if parts[wholeMatch] != s {
-		return s,
-			fmt.Errorf("only a part of the pre-release ID (%q) is matched: %q",
-				s, parts[wholeMatch])
-	}
-
-	if len(parts) != expectedLen {
-		return s, errors.New("the pre-release ID ('" +
-			s +
-			"') should be split into a (possibly empty) prefix," +
-			" one or more digits and a (possibly empty) suffix")
-	}
-
-	prefix, numStr, suffix := parts[prefixIdx], parts[numIdx], parts[suffixIdx]
-
-	num, err := strconv.Atoi(numStr)
-	if err != nil {
-		return s, errors.New(
-			"cannot convert the numeric part of the pre-release ID '" +
-				numStr +
-				"' into a number")
-	}
-
-	num++
-
-	if parts[1] == "" && parts[3] == "" {
-		s = strconv.Itoa(num)
-	} else {
-		format := prefix + "%0" + strconv.Itoa(len(numStr)) + "d" + suffix
-		s = fmt.Sprintf(format, num)
-	}
-
-	return s, nil
-}
-
 // clearSemverIDs clears the pre-release or build IDs according to the
 // setting of the clearIDs parameter.
 func (prog *prog) clearSemverIDs() error {
@@ -235,12 +279,20 @@ func (prog *prog) setIDs() error {
 
 	sv := &prog.semverVals.SemVer
 
-	if bIDs := prog.semverVals.BuildIDs; len(bIDs) > 0 {
-		err := semver.CheckRules(bIDs, prog.semverChecks.BuildIDChecks)
+	bIDs := prog.semverVals.BuildIDs
+	if prog.buildIDTemplate != "" {
+		bIDs, err = prog.templateBuildIDs(prog.newBuildEnv())
+		if err != nil {
+			return err
+		}
+	} else if len(bIDs) > 0 {
+		err = semver.CheckRules(bIDs, prog.semverChecks.BuildIDChecks)
 		if err != nil {
 			return errors.New("bad Build IDs: " + err.Error())
 		}
+	}
 
+	if len(bIDs) > 0 {
 		err = sv.SetBuildIDs(bIDs)
 		if err != nil {
 			return errors.New("cannot set Build IDs: " + err.Error())
@@ -299,6 +351,23 @@ func addParams(prog *prog) param.PSetOptFunc {
 					incrLeast: "increment the PRID if the semantic" +
 						" version number has one, otherwise increment the" +
 						" patch version",
+					incrAuto: "choose the part to increment from the" +
+						" commit messages, which are expected to follow" +
+						" the Conventional Commits rules. A breaking" +
+						" change (a '!' after the type or scope or a" +
+						" 'BREAKING CHANGE:' footer) increments the" +
+						" major version, a 'feat' commit increments the" +
+						" minor version and a 'fix' or 'perf' commit" +
+						" increments the patch version. The largest" +
+						" increment needed by any commit is used. While" +
+						" the major version is 0 a breaking change only" +
+						" increments the minor version. If no commit" +
+						" needs a new version then nothing is incremented",
+					incrNextStage: "move the PRID to the first" +
+						" pre-release of the next stage on the ladder of" +
+						" pre-release stages. So, for instance" +
+						" 'alpha.3' changes to 'beta.1' and, at the last" +
+						" stage, 'rc.2' changes to the release",
 				},
 			},
 			"which part of the "+semver.Name+" should be incremented."+
@@ -338,6 +407,127 @@ func addParams(prog *prog) param.PSetOptFunc {
 			param.PostAction(countIncrParams),
 		)
 
+		ps.Add("auto", psetter.Nil{},
+			"choose the part of the "+semver.Name+" to update from the"+
+				" commit messages",
+			param.PostAction(
+				paction.SetVal(&prog.incrPart, incrAuto)),
+			param.PostAction(countIncrParams),
+			param.SeeAlso(paramNameGitRepo, paramNameCommitsFile),
+		)
+
+		ps.Add(paramNameGitRepo,
+			psetter.Pathname{
+				Value:       &prog.gitRepo,
+				Expectation: filecheck.DirExists(),
+			},
+			"the local git repository in this directory is used to"+
+				" find the commit messages and the values of the"+
+				" git placeholders in a build ID template. The commits"+
+				" read are those made since the last tag; that is,"+
+				" those reachable from HEAD but not from any commit"+
+				" with a tag which is a "+semver.Name+". If this is"+
+				" not given the build ID template uses the repository"+
+				" in the current directory",
+			param.AltNames("commits-git-repo", "git"),
+			param.SeeAlso(paramNameCommitsFile, paramNameBuildIDTemplate),
+		)
+
+		ps.Add(paramNameCommitsFile,
+			psetter.Pathname{
+				Value:       &prog.commitsFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"read the commit messages from this file rather than from"+
+				" the standard input. If the text holds any NUL"+
+				" characters (as produced by 'git log -z') the messages"+
+				" are separated by them, otherwise each line is taken"+
+				" to be a separate message (as produced by"+
+				" 'git log --format=%s')",
+			param.SeeAlso(paramNameGitRepo),
+		)
+
+		ps.Add(paramNameShowReason, psetter.Bool{Value: &prog.showReason},
+			"print the part chosen from the commit messages and the"+
+				" reason for the choice on the standard error",
+			param.AltNames("reason", "explain"),
+		)
+
+		ps.Add(incrNextStage, psetter.Nil{},
+			"move the "+semver.Name+" to the next pre-release stage"+
+				" on the ladder of pre-release stages. The new"+
+				" pre-release IDs are the first of that stage so, for"+
+				" instance, 'v1.2.0-alpha.3' changes to 'v1.2.0-beta.1'"+
+				" and 'v1.2.0-alpha3' changes to 'v1.2.0-beta1'. At the"+
+				" last stage the next stage is the release so"+
+				" 'v1.2.0-rc.2' changes to 'v1.2.0'. It is an error if"+
+				" the "+semver.Name+" is already a release or its stage"+
+				" is not on the ladder",
+			param.PostAction(
+				paction.SetVal(&prog.incrPart, incrNextStage)),
+			param.PostAction(countIncrParams),
+			param.SeeAlso(paramNamePreRelLadder),
+		)
+
+		ps.Add(paramNamePreRelLadder,
+			psetter.StrList[string]{
+				Value: &prog.preRelLadder,
+				Checks: []check.ValCk[[]string]{
+					check.SliceLength[[]string](check.ValGT(0)),
+					check.SliceHasNoDups[[]string],
+					check.SliceAll[[]string](
+						check.StringMatchesPattern[string](
+							preRelStageRE,
+							"letters and hyphens")),
+				},
+			},
+			"the ordered list of pre-release stages. The stage of a"+
+				" pre-release is the first pre-release ID up to any"+
+				" digits so the stage of both 'rc.1' and 'rc1' is"+
+				" 'rc'. The stages are used to find the next stage",
+			param.AltNames("prid-ladder"),
+			param.SeeAlso(incrNextStage),
+		)
+
+		ps.Add(paramNameExisting,
+			psetter.Enum[string]{
+				Value: &prog.existingFrom,
+				AllowedVals: psetter.AllowedVals[string]{
+					existingNone: "don't check for existing versions",
+					existingStdin: "read the existing versions from" +
+						" the standard input, one per line",
+					existingGit: "take the existing versions from the" +
+						" tags in the local git repository. Any tags" +
+						" which are not " + semver.Names +
+						" are ignored",
+				},
+			},
+			"where to find the versions which already exist. If the new"+
+				" "+semver.Name+" already exists it is incremented"+
+				" until it does not: the PRID is incremented if it has"+
+				" one, otherwise the patch version is incremented. So,"+
+				" for instance, if 'rc.1' to 'rc.3' exist then 'rc.4'"+
+				" is given. It is an error if the new "+semver.Name+
+				" is a pre-release of a version which already exists"+
+				" or if the "+paramNameRelease+" parameter is given"+
+				" and the release already exists."+
+				" Build IDs are ignored when comparing the versions",
+			param.AltNames("avoid-existing"),
+			param.SeeAlso(paramNameExistingFile, paramNameGitRepo),
+		)
+
+		ps.Add(paramNameExistingFile,
+			psetter.Pathname{
+				Value:       &prog.existingFile,
+				Expectation: filecheck.FileExists(),
+			},
+			"read the versions which already exist from this file, one"+
+				" per line. Blank lines and lines starting with '#'"+
+				" are ignored and a version may be given with or"+
+				" without a leading 'v'",
+			param.SeeAlso(paramNameExisting),
+		)
+
 		ps.Add("incr-prid", psetter.Nil{},
 			"update the prid part of the "+semver.Name,
 			param.PostAction(
@@ -345,6 +535,80 @@ func addParams(prog *prog) param.PSetOptFunc {
 			param.PostAction(countIncrParams),
 		)
 
+		ps.Add(paramNameFile,
+			psetter.Pathname{
+				Value:       &prog.fileName,
+				Expectation: filecheck.FileExists(),
+			},
+			"read the "+semver.Name+" from this file and write the new"+
+				" value back to it. Only the version is changed, the"+
+				" rest of the file is left as it is. The version may"+
+				" be given with or without a leading 'v' and the new"+
+				" version is written in the same way. The kind of file"+
+				" is chosen from its name unless it is given by the "+
+				paramNameFileType+" parameter",
+			param.AltNames("version-file"),
+			param.SeeAlso(paramNameFileType),
+		)
+
+		ps.Add(paramNameFileType,
+			psetter.Enum[string]{
+				Value: &prog.fileType,
+				AllowedVals: psetter.AllowedVals[string]{
+					fileTypeAuto: "choose the kind of file from its name." +
+						" A file called " + fileTypeJSON + ", " +
+						fileTypeCargo + " or " + fileTypePyproject +
+						" is of that kind, a file with a '.go' suffix" +
+						" is a Go file and any other file is a plain file",
+					fileTypePlain: "the file holds just the version," +
+						" as in a VERSION file",
+					fileTypeGo: "a Go file with a single declaration" +
+						" of the Version constant, such as:" +
+						" const Version = \"v1.2.3\"",
+					fileTypeJSON: "a JSON file, such as an npm" +
+						" package.json, with a top-level version member",
+					fileTypeCargo: "a Rust Cargo.toml file with a" +
+						" version in the [package] or" +
+						" [workspace.package] table",
+					fileTypePyproject: "a Python pyproject.toml file" +
+						" with a version in the [project] or" +
+						" [tool.poetry] table",
+				},
+			},
+			"the kind of file from which the "+semver.Name+" is read",
+			param.SeeAlso(paramNameFile),
+		)
+
+		ps.Add(paramNameBuildIDTemplate,
+			psetter.String[string]{
+				Value: &prog.buildIDTemplate,
+				Checks: []check.ValCk[string]{
+					check.StringLength[string](check.ValGT(0)),
+				},
+			},
+			"set the build IDs from this template. The template is"+
+				" text with placeholders in braces which are replaced"+
+				" by their values; any characters in the values which"+
+				" are not allowed in a build ID are replaced by '-' and"+
+				" the result is split into build IDs at each '.'."+
+				" The placeholders are:"+
+				"\n{"+tmplDate+"[:layout]} - the UTC date, formatted"+
+				" using the Go time layout (default "+dfltDateLayout+")"+
+				"\n{"+tmplTime+"[:layout]} - the UTC time, formatted"+
+				" using the Go time layout (default "+dfltTimeLayout+")"+
+				"\n{"+tmplGitSHA+"[:n]} - the first n characters"+
+				" (default "+strconv.Itoa(dfltSHALen)+") of the hash of"+
+				" the commit at the HEAD of the git repository"+
+				"\n{"+tmplCounter+"} - the number of commits made since"+
+				" the last tag"+
+				"\n{"+tmplEnv+":NAME} - the value of the environment"+
+				" variable, which must be set"+
+				"\n\nFor instance: "+
+				"{date}.{time}.{gitsha:7}.{counter}.{env:BUILD_NUMBER}",
+			param.AltNames("build-template"),
+			param.SeeAlso(paramNameGitRepo),
+		)
+
 		ps.Add("clear-ids",
 			psetter.Enum[string]{
 				Value: &prog.clearIDs,
@@ -410,6 +674,10 @@ func addParams(prog *prog) param.PSetOptFunc {
 				&prog.setIDParamCounter))
 
 		ps.AddFinalCheck(checkReleaseVals(prog))
+		ps.AddFinalCheck(checkCommitVals(prog))
+		ps.AddFinalCheck(checkSemverSource(prog))
+		ps.AddFinalCheck(checkBuildIDSource(prog))
+		ps.AddFinalCheck(checkExistingVals(prog))
 
 		return nil
 	}
@@ -456,6 +724,116 @@ func checkReleaseVals(prog *prog) param.FinalCheckFunc {
 	}
 }
 
+// checkCommitVals checks the commit message parameters for consistency
+//
+// - the source of the commit messages and the reason for the choice of
+// part can only be given if the part is chosen from the commit messages
+//
+// - the git repository can only be given if it is used for the commit
+// messages, for a build ID template or for the existing versions
+//
+// - you cannot have more than one source of commit messages
+func checkCommitVals(prog *prog) param.FinalCheckFunc {
+	return func() error {
+		if prog.incrPart != incrAuto &&
+			(prog.commitsFile != "" || prog.showReason) {
+			return fmt.Errorf(
+				"the %q and %q parameters can only be given"+
+					" if the part to increment is %q",
+				paramNameCommitsFile, paramNameShowReason, incrAuto)
+		}
+
+		if prog.incrPart != incrAuto && prog.buildIDTemplate == "" &&
+			prog.existingFrom != existingGit && prog.gitRepo != "" {
+			return fmt.Errorf(
+				"the %q parameter can only be given"+
+					" if the part to increment is %q,"+
+					" the %q parameter is given"+
+					" or the %q parameter is %q",
+				paramNameGitRepo, incrAuto, paramNameBuildIDTemplate,
+				paramNameExisting, existingGit)
+		}
+
+		if prog.gitRepo != "" && prog.commitsFile != "" {
+			return fmt.Errorf(
+				"both %q and %q parameters have been set,"+
+					" only one or neither is allowed",
+				paramNameGitRepo, paramNameCommitsFile)
+		}
+
+		return nil
+	}
+}
+
+// checkSemverSource checks that the semver is given either directly or
+// from a file but not both
+func checkSemverSource(prog *prog) param.FinalCheckFunc {
+	return func() error {
+		svSet := prog.semverVals.SemVerHasBeenSet()
+
+		if svSet && prog.fileName != "" {
+			return fmt.Errorf(
+				"the %s has been given and the %q parameter has been"+
+					" set, only one is allowed",
+				semver.Name, paramNameFile)
+		}
+
+		if !svSet && prog.fileName == "" {
+			return fmt.Errorf(
+				"the %s must be given or read from a file using the %q"+
+					" parameter",
+				semver.Name, paramNameFile)
+		}
+
+		return nil
+	}
+}
+
+// checkBuildIDSource checks that the build IDs are given either directly
+// or from a template but not both
+func checkBuildIDSource(prog *prog) param.FinalCheckFunc {
+	return func() error {
+		if prog.buildIDTemplate != "" &&
+			prog.semverVals.BuildIDsHaveBeenSet() {
+			return fmt.Errorf(
+				"build IDs have been given and the %q parameter has been"+
+					" set, only one is allowed",
+				paramNameBuildIDTemplate)
+		}
+
+		return nil
+	}
+}
+
+// checkExistingVals checks the existing version parameters for consistency
+//
+// - you cannot have more than one source of existing versions
+//
+// - you cannot read both the existing versions and the commit messages
+// from the standard input
+func checkExistingVals(prog *prog) param.FinalCheckFunc {
+	return func() error {
+		if prog.existingFile != "" && prog.existingFrom != existingNone {
+			return fmt.Errorf(
+				"both %q and %q parameters have been set,"+
+					" only one or neither is allowed",
+				paramNameExisting, paramNameExistingFile)
+		}
+
+		if prog.existingFrom == existingStdin &&
+			prog.incrPart == incrAuto &&
+			prog.gitRepo == "" && prog.commitsFile == "" {
+			return fmt.Errorf(
+				"the existing versions and the commit messages"+
+					" cannot both be read from the standard input."+
+					" Use the %q or %q parameters",
+				paramNameExistingFile, paramNameCommitsFile)
+		}
+
+		return nil
+	}
+}
+
 // checkCounter returns an error if more than one of the parameters counted
 // by counter has been set.
 func checkCounter(name string, counter *paction.Counter) param.FinalCheckFunc {
@@ -8,60 +8,6 @@ import (
 	"github.com/nickwells/testhelper.mod/v2/testhelper"
 )
 
-func TestIncrPRID(t *testing.T) {
-	testCases := []struct {
-		testhelper.ID
-		testhelper.ExpErr
-		prid         string
-		pridExpected string
-	}{
-		{
-			ID:           testhelper.MkID("good - whole number"),
-			prid:         "12",
-			pridExpected: "13",
-		},
-		{
-			ID:           testhelper.MkID("good - with prefix"),
-			prid:         "RC12",
-			pridExpected: "RC13",
-		},
-		{
-			ID:           testhelper.MkID("good - with prefix and leading zeros"),
-			prid:         "RC0012",
-			pridExpected: "RC0013",
-		},
-		{
-			ID:           testhelper.MkID("good - with suffix"),
-			prid:         "12-RC",
-			pridExpected: "13-RC",
-		},
-		{
-			ID:           testhelper.MkID("good - with prefix and suffix"),
-			prid:         "RC12-RC",
-			pridExpected: "RC13-RC",
-		},
-		{
-			ID:           testhelper.MkID("bad - no numeric part"),
-			prid:         "RC-RC",
-			pridExpected: "RC-RC",
-			ExpErr: testhelper.MkExpErr(
-				`the string ("RC-RC") has no numerical part`),
-		},
-	}
-
-	for _, tc := range testCases {
-		s, err := incrNumInStr(tc.prid)
-		if s != tc.pridExpected {
-			t.Log(tc.IDStr())
-			t.Log("\t: expected: '" + tc.pridExpected + "'")
-			t.Log("\t:      got: '" + s + "'")
-			t.Errorf("\t: unexpected value of incremented prid\n")
-		}
-
-		testhelper.CheckExpErr(t, err, tc)
-	}
-}
-
 func TestSetIDs(t *testing.T) {
 	prIDsInit := []string{"prID"}
 	prIDsNew := []string{"new-prID"}
@@ -191,6 +137,13 @@ func TestIncr(t *testing.T) {
 			svExpected: semver.NewSVOrPanic(1, 2, 3, prIDs, bIDs),
 			ExpErr:     testhelper.MkExpErr(`unknown increment choice: "bad"`),
 		},
+		{
+			ID:         testhelper.MkID("auto - not chosen"),
+			incrPart:   incrAuto,
+			svExpected: semver.NewSVOrPanic(1, 2, 3, prIDs, bIDs),
+			ExpErr: testhelper.MkExpErr("the part to increment has not" +
+				" been chosen from the commit messages"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -217,3 +170,46 @@ func TestIncr(t *testing.T) {
 		}
 	}
 }
+
+func TestIncrNextStage(t *testing.T) {
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		sv         *semver.SV
+		svExpected *semver.SV
+	}{
+		{
+			ID:         testhelper.MkID("alpha to beta"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, []string{"alpha", "3"}, nil),
+			svExpected: semver.NewSVOrPanic(1, 2, 0, []string{"beta", "1"}, nil),
+		},
+		{
+			ID:         testhelper.MkID("rc to release"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, []string{"rc", "2"}, nil),
+			svExpected: semver.NewSVOrPanic(1, 2, 0, nil, nil),
+		},
+		{
+			ID:         testhelper.MkID("release"),
+			sv:         semver.NewSVOrPanic(1, 2, 0, nil, nil),
+			svExpected: semver.NewSVOrPanic(1, 2, 0, nil, nil),
+			ExpErr: testhelper.MkExpErr(
+				"there is no further pre-release stage"),
+		},
+	}
+
+	for _, tc := range testCases {
+		si := newProg()
+		si.semverVals.SemVer = *tc.sv
+		si.incrPart = incrNextStage
+
+		err := si.incr()
+		testhelper.CheckExpErr(t, err, tc)
+
+		if !semver.Equals(&si.semverVals.SemVer, tc.svExpected) {
+			t.Log(tc.IDStr())
+			t.Logf("\t: expected: %s", tc.svExpected)
+			t.Logf("\t:      got: %s", si.semverVals.SemVer)
+			t.Errorf("\t: unexpected next stage\n")
+		}
+	}
+}
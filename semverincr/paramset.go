@@ -24,11 +24,16 @@ func makeParamSet(prog *prog) *param.PSet {
 		param.SetProgramDescription(
 			"This provides tools for manipulating "+semver.Names+
 				". You can increment the various parts and set or clear"+
-				" the pre-release and build IDs.\n\n"+
+				" the pre-release and build IDs. The "+semver.Name+
+				" may be given directly or read from a project file,"+
+				" in which case the new value is written back to the"+
+				" file.\n\n"+
 				"Alternatively you can supply"+
 				" the '"+paramNameReleaseCandidate+"'"+
 				" or '"+paramNameRelease+"' parameters"+
 				" to start or finish a"+
-				"sequence of pre-releases"),
+				" sequence of pre-releases and the"+
+				" '"+incrNextStage+"' parameter to move through"+
+				" the stages of the pre-releases"),
 	)
 }
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+)
+
+// These are the kinds of file from which the version can be read
+const (
+	fileTypeAuto      = "auto"
+	fileTypePlain     = "plain"
+	fileTypeGo        = "go"
+	fileTypeJSON      = "package.json"
+	fileTypeCargo     = "Cargo.toml"
+	fileTypePyproject = "pyproject.toml"
+)
+
+// fileType returns the kind of the file. If the kind has not been given it
+// is chosen from the name of the file.
+func fileType(name, fType string) string {
+	if fType != fileTypeAuto {
+		return fType
+	}
+
+	base := filepath.Base(name)
+
+	switch {
+	case base == fileTypeJSON, base == fileTypeCargo, base == fileTypePyproject:
+		return base
+	case strings.HasSuffix(base, ".go"):
+		return fileTypeGo
+	}
+
+	return fileTypePlain
+}
+
+// versionFile records the file holding the version and where in the file
+// the version is
+type versionFile struct {
+	name       string
+	content    []byte
+	start, end int
+	hasV       bool
+}
+
+// vsnSpan gives the start and end offsets of the version in the content
+type vsnSpan struct {
+	start, end int
+}
+
+// plainVsnRE matches the content of a plain file holding just the version
+var plainVsnRE = regexp.MustCompile(`^\s*(\S+)\s*$`)
+
+// findPlainVsn finds the version in a file holding just the version
+func findPlainVsn(content []byte) (vsnSpan, error) {
+	m := plainVsnRE.FindSubmatchIndex(content)
+	if m == nil {
+		return vsnSpan{}, errors.New("the file should hold just the version")
+	}
+
+	return vsnSpan{m[2], m[3]}, nil
+}
+
+// goVsnRE matches a Go declaration of the Version constant, either on its
+// own or in a const block
+var goVsnRE = regexp.MustCompile(
+	`(?m)^\s*(?:const\s+)?Version\s*(?:string\s*)?=\s*"([^"\n]*)"`)
+
+// findGoVsn finds the version in a Go file. There must be exactly one
+// declaration of the Version constant.
+func findGoVsn(content []byte) (vsnSpan, error) {
+	ms := goVsnRE.FindAllSubmatchIndex(content, -1)
+
+	switch len(ms) {
+	case 0:
+		return vsnSpan{},
+			errors.New(`there is no declaration like: const Version = "..."`)
+	case 1:
+		return vsnSpan{ms[0][2], ms[0][3]}, nil
+	}
+
+	return vsnSpan{}, fmt.Errorf("the Version is declared %d times", len(ms))
+}
+
+// findJSONVsn finds the value of the top-level "version" member in a JSON
+// file such as package.json
+func findJSONVsn(content []byte) (vsnSpan, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	depth := 0
+	isKey := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return vsnSpan{}, errors.New(`there is no top-level "version"`)
+		}
+
+		if err != nil {
+			return vsnSpan{}, err
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			if t == '{' || t == '[' {
+				depth++
+			} else {
+				depth--
+			}
+
+			// a member name follows the start of the top-level object
+			// and the end of any object or array which is a member value
+			isKey = depth == 1
+
+			continue
+		case string:
+			if depth == 1 && isKey && t == "version" {
+				return jsonStrSpan(dec, content)
+			}
+		}
+
+		if depth == 1 {
+			isKey = !isKey
+		}
+	}
+}
+
+// jsonStrSpan reads the next token, which must be a string, and returns
+// its position in the content, without the quotes
+func jsonStrSpan(dec *json.Decoder, content []byte) (vsnSpan, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return vsnSpan{}, err
+	}
+
+	if _, ok := tok.(string); !ok {
+		return vsnSpan{}, errors.New(`the "version" is not a string`)
+	}
+
+	end := int(dec.InputOffset()) - 1
+
+	start := bytes.LastIndexByte(content[:end], '"') + 1
+	if start == 0 {
+		return vsnSpan{}, errors.New(`cannot find the "version"`)
+	}
+
+	return vsnSpan{start, end}, nil
+}
+
+// tomlTableRE matches a TOML table header
+var tomlTableRE = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(?:#.*)?$`)
+
+// tomlVsnRE matches the version key in a TOML table
+var tomlVsnRE = regexp.MustCompile(`^\s*version\s*=\s*"([^"]*)"`)
+
+// findTOMLVsn returns a function which finds the version key in the first
+// of the given tables of a TOML file
+func findTOMLVsn(tables ...string) func([]byte) (vsnSpan, error) {
+	return func(content []byte) (vsnSpan, error) {
+		table := ""
+		offset := 0
+
+		for _, rawLine := range bytes.SplitAfter(content, []byte("\n")) {
+			line := strings.TrimRight(string(rawLine), "\r\n")
+
+			if m := tomlTableRE.FindStringSubmatch(line); m != nil {
+				table = strings.TrimSpace(m[1])
+			} else if m := tomlVsnRE.FindStringSubmatchIndex(line); m != nil {
+				for _, t := range tables {
+					if t == table {
+						return vsnSpan{offset + m[2], offset + m[3]}, nil
+					}
+				}
+			}
+
+			offset += len(rawLine)
+		}
+
+		return vsnSpan{}, fmt.Errorf(
+			"there is no version in the [%s] table",
+			strings.Join(tables, "] or ["))
+	}
+}
+
+// vsnFinders maps the kind of file to the function which finds the version
+var vsnFinders = map[string]func([]byte) (vsnSpan, error){
+	fileTypePlain:     findPlainVsn,
+	fileTypeGo:        findGoVsn,
+	fileTypeJSON:      findJSONVsn,
+	fileTypeCargo:     findTOMLVsn("package", "workspace.package"),
+	fileTypePyproject: findTOMLVsn("project", "tool.poetry"),
+}
+
+// readVersionFile reads the file and finds the version in it. It returns
+// the versionFile and the semver it holds. The version may be given with
+// or without a leading 'v'.
+func readVersionFile(name, fType string) (*versionFile, *semver.SV, error) {
+	content, err := os.ReadFile(name) //nolint:gosec
+	if err != nil {
+		return nil, nil, err
+	}
+
+	span, err := vsnFinders[fileType(name, fType)](content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	vf := &versionFile{
+		name:    name,
+		content: content,
+		start:   span.start,
+		end:     span.end,
+	}
+
+	vsn := string(content[span.start:span.end])
+	vf.hasV = strings.HasPrefix(vsn, "v")
+
+	sv, err := semver.ParseStrictSV(strings.TrimPrefix(vsn, "v"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return vf, sv, nil
+}
+
+// newContent returns the content of the file with the version replaced by
+// the semver. The semver is given a leading 'v' only if the original
+// version had one.
+func (vf versionFile) newContent(sv *semver.SV) []byte {
+	vsn := sv.String()
+	if !vf.hasV {
+		vsn = strings.TrimPrefix(vsn, "v")
+	}
+
+	content := make([]byte, 0, len(vf.content)+len(vsn))
+	content = append(content, vf.content[:vf.start]...)
+	content = append(content, vsn...)
+	content = append(content, vf.content[vf.end:]...)
+
+	return content
+}
+
+// write replaces the version in the file with the semver. The new content
+// is written to a temporary file which then replaces the original so that
+// the file is never left partly written.
+func (vf versionFile) write(sv *semver.SV) error {
+	info, err := os.Stat(vf.name)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(vf.name),
+		"."+filepath.Base(vf.name)+".*")
+	if err != nil {
+		return err
+	}
+
+	tmpName := tmp.Name()
+
+	_, err = tmp.Write(vf.newContent(sv))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmpName, info.Mode().Perm())
+	}
+
+	if err == nil {
+		err = os.Rename(tmpName, vf.name)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("cannot rewrite %s: %w", vf.name, err)
+	}
+
+	return nil
+}
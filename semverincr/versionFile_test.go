@@ -0,0 +1,173 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nickwells/semver.mod/v3/semver"
+	"github.com/nickwells/testhelper.mod/v2/testhelper"
+)
+
+func TestVersionFile(t *testing.T) {
+	newSV := semver.NewSVOrPanic(1, 3, 0, []string{"rc", "1"}, nil)
+
+	testCases := []struct {
+		testhelper.ID
+		testhelper.ExpErr
+		name       string
+		fType      string
+		content    string
+		expSV      string
+		expContent string
+	}{
+		{
+			ID:         testhelper.MkID("plain"),
+			name:       "VERSION",
+			content:    "1.2.3\n",
+			expSV:      "v1.2.3",
+			expContent: "1.3.0-rc.1\n",
+		},
+		{
+			ID:         testhelper.MkID("plain with v"),
+			name:       "VERSION",
+			content:    "  v1.2.3+build.7  \n",
+			expSV:      "v1.2.3+build.7",
+			expContent: "  v1.3.0-rc.1  \n",
+		},
+		{
+			ID:      testhelper.MkID("plain, two values"),
+			name:    "VERSION",
+			content: "1.2.3\n1.2.4\n",
+			ExpErr:  testhelper.MkExpErr("the file should hold just the version"),
+		},
+		{
+			ID:   testhelper.MkID("go"),
+			name: "version.go",
+			content: "package v\n\n// Version is the version\n" +
+				"const Version = \"v1.2.3\" // the version\n",
+			expSV: "v1.2.3",
+			expContent: "package v\n\n// Version is the version\n" +
+				"const Version = \"v1.3.0-rc.1\" // the version\n",
+		},
+		{
+			ID:   testhelper.MkID("go, const block"),
+			name: "version.go",
+			content: "package v\n\nconst (\n" +
+				"\tName            = \"x\"\n" +
+				"\tVersion string = \"1.2.3\"\n)\n",
+			expSV: "v1.2.3",
+			expContent: "package v\n\nconst (\n" +
+				"\tName            = \"x\"\n" +
+				"\tVersion string = \"1.3.0-rc.1\"\n)\n",
+		},
+		{
+			ID:      testhelper.MkID("go, no Version"),
+			name:    "version.go",
+			content: "package v\n\nconst Vsn = \"1.2.3\"\n",
+			ExpErr: testhelper.MkExpErr(
+				`there is no declaration like: const Version = "..."`),
+		},
+		{
+			ID:   testhelper.MkID("package.json"),
+			name: "package.json",
+			content: "{\n  \"name\": \"x\",\n" +
+				"  \"dependencies\": {\"version\": \"9.9.9\"},\n" +
+				"  \"files\": [\"a\", \"b\"],\n" +
+				"  \"version\": \"1.2.3\",\n" +
+				"  \"private\": true\n}\n",
+			expSV: "v1.2.3",
+			expContent: "{\n  \"name\": \"x\",\n" +
+				"  \"dependencies\": {\"version\": \"9.9.9\"},\n" +
+				"  \"files\": [\"a\", \"b\"],\n" +
+				"  \"version\": \"1.3.0-rc.1\",\n" +
+				"  \"private\": true\n}\n",
+		},
+		{
+			ID:      testhelper.MkID("package.json, nested only"),
+			name:    "package.json",
+			content: "{\"a\": {\"version\": \"1.2.3\"}, \"b\": \"version\"}",
+			ExpErr:  testhelper.MkExpErr(`there is no top-level "version"`),
+		},
+		{
+			ID:   testhelper.MkID("Cargo.toml"),
+			name: "Cargo.toml",
+			content: "[package]\r\nname = \"x\"\r\n" +
+				"version = \"1.2.3\"   # the version\r\n\r\n" +
+				"[dependencies]\r\nserde = { version = \"1.0\" }\r\n",
+			expSV: "v1.2.3",
+			expContent: "[package]\r\nname = \"x\"\r\n" +
+				"version = \"1.3.0-rc.1\"   # the version\r\n\r\n" +
+				"[dependencies]\r\nserde = { version = \"1.0\" }\r\n",
+		},
+		{
+			ID:   testhelper.MkID("pyproject.toml"),
+			name: "pyproject.toml",
+			content: "[build-system]\nversion = \"0.0.1\"\n\n" +
+				"[project]\nname = \"x\"\nversion = \"1.2.3\"\n",
+			expSV: "v1.2.3",
+			expContent: "[build-system]\nversion = \"0.0.1\"\n\n" +
+				"[project]\nname = \"x\"\nversion = \"1.3.0-rc.1\"\n",
+		},
+		{
+			ID:      testhelper.MkID("pyproject.toml, no project version"),
+			name:    "pyproject.toml",
+			content: "[project]\nname = \"x\"\ndynamic = [\"version\"]\n",
+			ExpErr: testhelper.MkExpErr(
+				"there is no version in the [project] or [tool.poetry] table"),
+		},
+		{
+			ID:         testhelper.MkID("given type"),
+			name:       "version.txt",
+			fType:      fileTypeCargo,
+			content:    "[workspace.package]\nversion = \"1.2.3\"\n",
+			expSV:      "v1.2.3",
+			expContent: "[workspace.package]\nversion = \"1.3.0-rc.1\"\n",
+		},
+		{
+			ID:      testhelper.MkID("bad semver"),
+			name:    "VERSION",
+			content: "1.2\n",
+			ExpErr:  testhelper.MkExpErr("bad semantic version ID"),
+		},
+	}
+
+	for _, tc := range testCases {
+		fName := filepath.Join(t.TempDir(), tc.name)
+		if err := os.WriteFile(fName, []byte(tc.content), 0o640); err != nil {
+			t.Fatal(tc.IDStr(), ": cannot write the file:", err)
+		}
+
+		fType := tc.fType
+		if fType == "" {
+			fType = fileTypeAuto
+		}
+
+		vf, sv, err := readVersionFile(fName, fType)
+		if !testhelper.CheckExpErr(t, err, tc) || err != nil {
+			continue
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "semver", sv.String(), tc.expSV)
+
+		if err := vf.write(newSV); err != nil {
+			t.Fatal(tc.IDStr(), ": cannot rewrite the file:", err)
+		}
+
+		content, err := os.ReadFile(fName)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot read the rewritten file:", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "content",
+			string(content), tc.expContent)
+
+		info, err := os.Stat(fName)
+		if err != nil {
+			t.Fatal(tc.IDStr(), ": cannot stat the rewritten file:", err)
+		}
+
+		testhelper.DiffString(t, tc.IDStr(), "mode",
+			info.Mode().Perm().String(), "-rw-r-----")
+	}
+}